@@ -18,6 +18,7 @@ package ffjson
  */
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	fflib "github.com/yingshengtech/ffjson/fflib/v1"
@@ -29,7 +30,13 @@ import (
 // This is a reusable decoder.
 // This should not be used by more than one goroutine at the time.
 type Decoder struct {
-	fs *fflib.FFLexer
+	fs        *fflib.FFLexer
+	useNumber bool
+	strict    bool
+	relaxed   bool
+	limits    fflib.Limits
+	dupKeys   fflib.DuplicateKeys
+	utf8      fflib.UTF8
 }
 
 // NewDecoder returns a reusable Decoder.
@@ -37,22 +44,94 @@ func NewDecoder() *Decoder {
 	return &Decoder{}
 }
 
+// UseNumber causes the Decoder to unmarshal a number into an interface{} as a
+// json.Number instead of as a float64.
+func (d *Decoder) UseNumber() {
+	d.useNumber = true
+}
+
+// UseStrict makes the Decoder reject input RFC 8259 does not allow, like
+// comments, \v and \f as whitespace, trailing commas, mismatched nesting
+// and data after the top-level value. Generated decoders check the input
+// while decoding it; types without one get it checked with fflib.Valid
+// first.
+func (d *Decoder) UseStrict() {
+	d.strict = true
+}
+
+// UseRelaxed makes the Decoder accept the JSON5-style input of hand-edited
+// files, like comments, trailing commas, single-quoted strings and unquoted
+// keys. See fflib.FFLexer.Relaxed. Types without a generated decoder get
+// the input rewritten as JSON.
+func (d *Decoder) UseRelaxed() {
+	d.relaxed = true
+}
+
+// SetLimits bounds the input the Decoder accepts, against hostile
+// documents. Errors over a limit hold a *fflib.SizeLimitError,
+// *fflib.DepthLimitError, *fflib.StringLimitError or
+// *fflib.ElementLimitError. Types without a generated decoder get the
+// input checked with fflib.Limits.Check first.
+func (d *Decoder) SetLimits(l fflib.Limits) {
+	d.limits = l
+}
+
+// SetDuplicateKeys selects how generated decoders handle a key appearing
+// twice in an object. By default the last value wins, like encoding/json;
+// fflib.DuplicateKeysKnown and fflib.DuplicateKeysAll reject duplicates
+// with a *fflib.DuplicateKeyError. Types without a generated decoder are
+// not checked.
+func (d *Decoder) SetDuplicateKeys(policy fflib.DuplicateKeys) {
+	d.dupKeys = policy
+}
+
+// SetUTF8 selects how generated decoders handle invalid UTF-8 in strings.
+// By default it is replaced with U+FFFD, like encoding/json;
+// fflib.UTF8Reject fails with an error holding a *fflib.InvalidUTF8Error
+// and fflib.UTF8Pass keeps the raw bytes. Types without a generated decoder
+// always get U+FFFD.
+func (d *Decoder) SetUTF8(policy fflib.UTF8) {
+	d.utf8 = policy
+}
+
 // Decode the data in the supplied data slice.
 func (d *Decoder) Decode(data []byte, v interface{}) error {
 	f, ok := v.(unmarshalFaster)
 	if ok {
-		if d.fs == nil {
-			d.fs = fflib.NewFFLexer(data)
-		} else {
-			d.fs.Reset(data)
+		return d.decodeFast(data, f)
+	}
+
+	if d.relaxed {
+		var err error
+		data, err = fflib.RelaxedToJSON(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.limits != (fflib.Limits{}) {
+		if err := d.limits.Check(data); err != nil {
+			return err
+		}
+	}
+
+	// UnmarshalJSON methods and json.Decoder accept more than RFC 8259,
+	// so strict input is checked up front, once it is within the limits.
+	if d.strict {
+		if err := fflib.Valid(data); err != nil {
+			return err
 		}
-		return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
 	}
 
 	um, ok := v.(json.Unmarshaler)
 	if ok {
 		return um.UnmarshalJSON(data)
 	}
+	if d.useNumber {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		return dec.Decode(v)
+	}
 	return json.Unmarshal(data, v)
 }
 
@@ -61,7 +140,14 @@ func (d *Decoder) Decode(data []byte, v interface{}) error {
 func (d *Decoder) DecodeReader(r io.Reader, v interface{}) error {
 	_, ok := v.(unmarshalFaster)
 	_, ok2 := v.(json.Unmarshaler)
-	if ok || ok2 {
+	// json.Decoder reads a stream of values, so strict mode needs the
+	// whole input to reject data after the first one. Relaxed input and
+	// limits are handled before decoding as well.
+	if ok || ok2 || d.strict || d.relaxed || d.limits != (fflib.Limits{}) {
+		if d.limits.MaxSize > 0 {
+			// Stop reading once the input is over the limit.
+			r = io.LimitReader(r, int64(d.limits.MaxSize)+1)
+		}
 		data, err := ioutil.ReadAll(r)
 		if err != nil {
 			return err
@@ -70,6 +156,9 @@ func (d *Decoder) DecodeReader(r io.Reader, v interface{}) error {
 		return d.Decode(data, v)
 	}
 	dec := json.NewDecoder(r)
+	if d.useNumber {
+		dec.UseNumber()
+	}
 	return dec.Decode(v)
 }
 
@@ -83,10 +172,32 @@ func (d *Decoder) DecodeFast(data []byte, v interface{}) error {
 	if !ok {
 		return errors.New("ffjson unmarshal not available for type " + reflect.TypeOf(v).String())
 	}
+	return d.decodeFast(data, f)
+}
+
+// decodeFast decodes data with the generated decoder of f. The strict lexer
+// checks the grammar while decoding, so only the end of input is left.
+func (d *Decoder) decodeFast(data []byte, f unmarshalFaster) error {
+	d.reset(data)
+	if err := f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start); err != nil {
+		return err
+	}
+	if d.strict {
+		return d.fs.ScanEOF()
+	}
+	return nil
+}
+
+func (d *Decoder) reset(data []byte) {
 	if d.fs == nil {
 		d.fs = fflib.NewFFLexer(data)
 	} else {
 		d.fs.Reset(data)
 	}
-	return f.UnmarshalJSONFFLexer(d.fs, fflib.FFParse_map_start)
+	d.fs.UseNumber = d.useNumber
+	d.fs.Strict = d.strict
+	d.fs.Relaxed = d.relaxed
+	d.fs.Limits = d.limits
+	d.fs.DuplicateKeys = d.dupKeys
+	d.fs.UTF8 = d.utf8
 }
@@ -29,15 +29,20 @@ import (
 // It allows to encode many objects to a single writer.
 // This should not be used by more than one goroutine at the time.
 type Encoder struct {
-	buf fflib.Buffer
-	w   io.Writer
-	enc *json.Encoder
+	buf    fflib.Buffer
+	ibuf   fflib.Buffer
+	w      io.Writer
+	enc    *json.Encoder
+	prefix string
+	indent string
 }
 
 // NewEncoder returns a reusable Encoder.
 // Output will be written to the supplied writer.
 func NewEncoder(w io.Writer) *Encoder {
-	return &Encoder{w: w, enc: json.NewEncoder(w)}
+	e := &Encoder{w: w}
+	e.enc = json.NewEncoder(asciiWriter{e})
+	return e
 }
 
 // Encode the data in the supplied value to the stream
@@ -52,6 +57,19 @@ func (e *Encoder) Encode(v interface{}) error {
 		if err != nil {
 			return err
 		}
+		if err = e.buf.Err(); err != nil {
+			return err
+		}
+
+		if e.prefix != "" || e.indent != "" {
+			e.ibuf.Reset()
+			err = fflib.WriteIndent(&e.ibuf, e.buf.Bytes(), e.prefix, e.indent)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(e.w, &e.ibuf)
+			return err
+		}
 
 		_, err = io.Copy(e.w, &e.buf)
 		return err
@@ -60,6 +78,64 @@ func (e *Encoder) Encode(v interface{}) error {
 	return e.enc.Encode(v)
 }
 
+// SetIndent makes the encoder indent every following value like
+// json.MarshalIndent with prefix and indent. Calling SetIndent("", "")
+// disables indentation.
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+	e.enc.SetIndent(prefix, indent)
+}
+
+// SetEscapeHTML specifies whether <, > and & are escaped in JSON strings,
+// like json.Encoder.SetEscapeHTML. The default is true.
+func (e *Encoder) SetEscapeHTML(on bool) {
+	e.setEscape(fflib.EscapeNoHTML, !on)
+	e.enc.SetEscapeHTML(on)
+}
+
+// SetEscapeASCII specifies whether all non-ASCII characters are escaped as
+// \uXXXX, for clients that do not handle UTF-8. The default is false.
+func (e *Encoder) SetEscapeASCII(on bool) {
+	e.setEscape(fflib.EscapeASCII, on)
+}
+
+// SetUTF8 selects how invalid UTF-8 in strings is encoded. By default it
+// is replaced with U+FFFD, like encoding/json; fflib.UTF8Reject fails with
+// a *fflib.InvalidUTF8Error and fflib.UTF8Pass writes the raw bytes. Types
+// without a generated encoder always get U+FFFD.
+func (e *Encoder) SetUTF8(policy fflib.UTF8) {
+	e.buf.SetUTF8(policy)
+	e.ibuf.SetUTF8(policy)
+}
+
+func (e *Encoder) setEscape(flag fflib.Escape, on bool) {
+	esc := e.buf.Escape() &^ flag
+	if on {
+		esc |= flag
+	}
+	e.buf.SetEscape(esc)
+	e.ibuf.SetEscape(esc)
+}
+
+// asciiWriter escapes the output of encoding/json, which has no ASCII
+// mode, when SetEscapeASCII is on.
+type asciiWriter struct {
+	e *Encoder
+}
+
+func (w asciiWriter) Write(p []byte) (int, error) {
+	if w.e.buf.Escape()&fflib.EscapeASCII == 0 {
+		return w.e.w.Write(p)
+	}
+	w.e.ibuf.Reset()
+	fflib.WriteASCII(&w.e.ibuf, p)
+	if _, err := w.e.w.Write(w.e.ibuf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
 // EncodeFast will unmarshal the data if fast marshall is available.
 // This function can be used if you want to be sure the fast
 // marshal is used or in testing.
@@ -75,6 +75,42 @@ func MarshalFast(v interface{}) ([]byte, error) {
 	return Marshal(v)
 }
 
+// MarshalIndent is like Marshal, but applies the indentation of
+// json.MarshalIndent to the output. Types with generated code stay on the
+// fast path; their output is re-indented while it is scanned.
+func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	b, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := fflib.Buffer{}
+	err = fflib.WriteIndent(&buf, b, prefix, indent)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalCanonical encodes v like Marshal, then rewrites the result in the
+// canonical form of RFC 8785, suitable for signing: no whitespace, object
+// keys sorted by UTF-16 code units, ECMAScript number formatting and the
+// minimal string escaping. Numbers are IEEE 754 doubles in this form, so
+// integers beyond 2^53 lose precision.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	b, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := fflib.Buffer{}
+	err = fflib.WriteCanonical(&buf, b)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 // Unmarshal will act the same way as json.Unmarshal, except
 // it will choose the ffjson unmarshal function before falling
 // back to using json.Unmarshal.
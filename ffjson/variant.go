@@ -0,0 +1,34 @@
+package ffjson
+
+/**
+ *  Copyright 2015 Paul Querna, Klaus Post
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+import (
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+)
+
+// RegisterVariant registers the type of proto as the variant called name
+// of the interface iface points to. Interface fields tagged
+// `ffjson:",variant"` are then encoded as JSON objects with a member key
+// holding name, and decoded into the registered type:
+//
+//	ffjson.RegisterVariant((*Shape)(nil), "kind", "circle", Circle{})
+//
+// Register &Circle{} to decode pointers instead of values.
+func RegisterVariant(iface interface{}, key string, name string, proto interface{}) {
+	fflib.RegisterVariant(iface, key, name, proto)
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"reflect"
 	"unicode/utf8"
 )
 
@@ -80,6 +81,10 @@ type Buffer struct {
 	runeBytes        [utf8.UTFMax]byte // avoid allocation of slice on each WriteByte or Rune
 	encoder          *json.Encoder
 	skipTrailingByte bool
+	escape           Escape
+	redact           bool
+	utf8             UTF8
+	err              error
 }
 
 // ErrTooLarge is passed to panic if memory cannot be allocated to store data in a buffer.
@@ -116,9 +121,11 @@ func (b *Buffer) Truncate(n int) {
 	}
 }
 
-// Reset resets the buffer so it has no content.
-// b.Reset() is the same as b.Truncate(0).
-func (b *Buffer) Reset() { b.Truncate(0) }
+// Reset resets the buffer so it has no content, and clears Err.
+func (b *Buffer) Reset() {
+	b.Truncate(0)
+	b.err = nil
+}
 
 // grow grows the buffer to guarantee space for n more bytes.
 // It returns the index where bytes should be written.
@@ -273,16 +280,88 @@ func (b *Buffer) Rewind(n int) error {
 	return nil
 }
 
+// Encode writes v like encoding/json. Values with a MarshalJSONBuf method,
+// as generated by ffjson, are written by it so the policies of b apply to
+// them. When redacting, values that may hold fields tagged with redact or
+// mask are walked to reach their generated code, see encodeRedacted.
 func (b *Buffer) Encode(v interface{}) error {
+	if m, ok := v.(variantMarshaler); ok {
+		return m.MarshalJSONBuf(b)
+	}
+	if b.redact && v != nil && mayRedact(reflect.TypeOf(v)) {
+		return encodeRedacted(b, reflect.ValueOf(v))
+	}
+	return b.encodeJSON(v)
+}
+
+// encodeJSON writes v with encoding/json.
+func (b *Buffer) encodeJSON(v interface{}) error {
 	if b.encoder == nil {
 		b.encoder = json.NewEncoder(b)
 	}
+	b.encoder.SetEscapeHTML(b.escape&EscapeNoHTML == 0)
+	start := b.Len()
 	b.skipTrailingByte = true
 	err := b.encoder.Encode(v)
 	b.skipTrailingByte = false
+	if err == nil && b.escape&EscapeASCII != 0 {
+		// encoding/json has no ASCII mode, escape its output afterwards.
+		out := b.Bytes()[start:]
+		for _, c := range out {
+			if c >= utf8.RuneSelf {
+				out = append([]byte(nil), out...)
+				b.Truncate(start)
+				WriteASCII(b, out)
+				break
+			}
+		}
+	}
 	return err
 }
 
+// SetEscape sets how strings written to the buffer by WriteJson and Encode
+// are escaped. It is kept across Reset.
+func (b *Buffer) SetEscape(e Escape) { b.escape = e }
+
+// Escape returns the escaping options set with SetEscape.
+func (b *Buffer) Escape() Escape { return b.escape }
+
+// SetRedact makes generated MarshalJSONBuf methods writing to the buffer
+// hide the fields tagged with redact or mask. It is kept across Reset.
+func (b *Buffer) SetRedact(on bool) { b.redact = on }
+
+// Redact reports if redaction was turned on with SetRedact.
+func (b *Buffer) Redact() bool { return b.redact }
+
+// SetUTF8 sets how WriteJson handles invalid UTF-8 in strings written to
+// the buffer. It is kept across Reset. Values written by Encode always get
+// U+FFFD, as encoding/json has no other mode.
+func (b *Buffer) SetUTF8(p UTF8) { b.utf8 = p }
+
+// UTF8 returns the policy set with SetUTF8.
+func (b *Buffer) UTF8() UTF8 { return b.utf8 }
+
+// Err returns the first *InvalidUTF8Error of a string written under
+// UTF8Reject since the last Reset. The string itself is written with
+// U+FFFD, so the output should be dropped.
+func (b *Buffer) Err() error { return b.err }
+
+// newBufferLike returns an empty Buffer with the escaping, redaction and
+// UTF-8 policies of buf, for a value written to buf in pieces.
+func newBufferLike(buf EncodingBuffer) *Buffer {
+	b := &Buffer{escape: escapeOf(buf), redact: Redacting(buf)}
+	if h, ok := buf.(utf8Handler); ok {
+		b.utf8 = h.UTF8()
+	}
+	return b
+}
+
+func (b *Buffer) failUTF8(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
 // WriteRune appends the UTF-8 encoding of Unicode code point r to the
 // buffer, returning its length and an error, which is always nil but is
 // included to match bufio.Writer's WriteRune. The buffer is grown as needed;
@@ -0,0 +1,181 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrCanonicalUTF8 is returned by WriteCanonical for strings that are not
+// valid UTF-8, which RFC 8785 does not allow.
+var ErrCanonicalUTF8 = errors.New("ffjson: canonical JSON string is not valid UTF-8")
+
+// WriteCanonical writes the JSON value in data in the canonical form of
+// RFC 8785 (JSON Canonicalization Scheme): no whitespace, object keys
+// sorted by their UTF-16 code units, numbers formatted like ECMAScript and
+// strings with the minimal escaping.
+func WriteCanonical(buf EncodingBuffer, data []byte) error {
+	fs := NewFFLexer(data)
+	// Invalid strings are rejected with ErrCanonicalUTF8.
+	fs.UTF8 = UTF8Pass
+	v, err := DecodeInterface(fs, fs.Scan())
+	if err != nil {
+		return err
+	}
+	if tok := fs.Scan(); tok != FFTok_eof {
+		return interfaceTokenError(fs, tok)
+	}
+	return writeCanonical(buf, v)
+}
+
+func writeCanonical(buf EncodingBuffer, v interface{}) error {
+	switch tv := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if tv {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case string:
+		return writeCanonicalString(buf, tv)
+	case float64:
+		return WriteCanonicalFloat(buf, tv)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range tv {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make(utf16Keys, 0, len(tv))
+		for k := range tv {
+			keys = append(keys, k)
+		}
+		sort.Sort(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalString(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, tv[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("ffjson: unexpected canonical value %T", v)
+	}
+	return nil
+}
+
+// WriteCanonicalFloat writes f like ECMAScript's Number.prototype.toString,
+// as RFC 8785 requires. That is the format of WriteFloat, except for -0.
+func WriteCanonicalFloat(buf EncodingBuffer, f float64) error {
+	if f == 0 {
+		buf.WriteByte('0')
+		return nil
+	}
+	return WriteFloat(buf, f, 64)
+}
+
+// writeCanonicalString escapes only '"', '\\' and control characters, the
+// latter with the short forms where JSON has them.
+func writeCanonicalString(buf EncodingBuffer, s string) error {
+	if !utf8.ValidString(s) {
+		return ErrCanonicalUTF8
+	}
+
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		buf.WriteString(s[start:i])
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xF])
+		}
+		start = i + 1
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+	return nil
+}
+
+// utf16Keys sorts object keys by their UTF-16 code units.
+type utf16Keys []string
+
+func (k utf16Keys) Len() int      { return len(k) }
+func (k utf16Keys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k utf16Keys) Less(i, j int) bool {
+	a, b := k[i], k[j]
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			ha, la := utf16Units(ra)
+			hb, lb := utf16Units(rb)
+			if ha != hb {
+				return ha < hb
+			}
+			return la < lb
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+func utf16Units(r rune) (rune, rune) {
+	if r < 0x10000 {
+		return r, 0
+	}
+	return utf16.EncodeRune(r)
+}
@@ -0,0 +1,65 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"math"
+	"testing"
+)
+
+func TestWriteCanonical(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		// Examples from RFC 8785.
+		{`{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh",
+		   "1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"},
+		{`{ "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		    "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/", "literals": [null, true, false] }`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"` +
+				"\u20ac$\\u000f\\nA'B\\\"\\\\\\\\\\\"/" + `"}`},
+		{`[-0, 1e21, 999999999999999900000, 0.000001, 1e-7, -5e-324, 9007199254740993]`,
+			`[0,1e+21,999999999999999900000,0.000001,1e-7,-5e-324,9007199254740992]`},
+		{`"<a&b>\u2028"`, "\"<a&b>\u2028\""},
+	}
+
+	for _, test := range tests {
+		var buf Buffer
+		if err := WriteCanonical(&buf, []byte(test.in)); err != nil {
+			t.Fatalf("WriteCanonical(%s): %v", test.in, err)
+		}
+		if buf.String() != test.expected {
+			t.Fatalf("Expected: %v\nGot: %v", test.expected, buf.String())
+		}
+	}
+
+	for _, bad := range []string{`{"a":1} 2`, `[1,]`, "\"\xff\""} {
+		var buf Buffer
+		if err := WriteCanonical(&buf, []byte(bad)); err == nil {
+			t.Fatalf("Expected error for %q, got: %v", bad, buf.String())
+		}
+	}
+
+	var buf Buffer
+	if err := WriteCanonicalFloat(&buf, math.NaN()); err == nil {
+		t.Fatalf("Expected error for NaN")
+	}
+}
@@ -0,0 +1,99 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// FieldChange describes a value whose JSON encoding differs between two
+// instances of a generated type, as returned by the generated Diff.
+type FieldChange struct {
+	// Path is the RFC 6901 JSON Pointer of the value.
+	Path string
+	// Old and New are the JSON encodings, nil if the value is omitted.
+	Old []byte
+	New []byte
+}
+
+// NewFieldChange returns a FieldChange holding copies of old and new.
+// Empty encodings are stored as nil.
+func NewFieldChange(path string, old []byte, new []byte) FieldChange {
+	fc := FieldChange{Path: path}
+	if len(old) > 0 {
+		fc.Old = append([]byte(nil), old...)
+	}
+	if len(new) > 0 {
+		fc.New = append([]byte(nil), new...)
+	}
+	return fc
+}
+
+// AppendFieldChanges appends the changes of a nested value to dst,
+// prefixing their paths with the pointer of the nested value.
+func AppendFieldChanges(dst []FieldChange, prefix string, src []FieldChange) []FieldChange {
+	for _, fc := range src {
+		fc.Path = prefix + fc.Path
+		dst = append(dst, fc)
+	}
+	return dst
+}
+
+// WriteJSONPatch writes changes as an RFC 6902 JSON Patch document.
+// Values that appear are "add"ed, values that disappear are "remove"d and
+// all others are "replace"d. If test is set, each change of an existing value
+// is preceded by a "test" operation against its old value.
+func WriteJSONPatch(buf JsonStringWriter, changes []FieldChange, test bool) {
+	buf.WriteByte('[')
+	for i, fc := range changes {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		if test && fc.Old != nil {
+			writePatchOp(buf, "test", fc.Path, fc.Old)
+			buf.WriteByte(',')
+		}
+
+		switch {
+		case fc.Old == nil:
+			writePatchOp(buf, "add", fc.Path, fc.New)
+		case fc.New == nil:
+			writePatchOp(buf, "remove", fc.Path, nil)
+		default:
+			writePatchOp(buf, "replace", fc.Path, fc.New)
+		}
+	}
+	buf.WriteByte(']')
+}
+
+func writePatchOp(buf JsonStringWriter, op string, path string, value []byte) {
+	buf.WriteString(`{"op":"`)
+	buf.WriteString(op)
+	buf.WriteString(`","path":`)
+	WriteJsonString(buf, path)
+	if value != nil {
+		buf.WriteString(`,"value":`)
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+}
+
+// JSONPatch returns changes as an RFC 6902 JSON Patch document,
+// see WriteJSONPatch.
+func JSONPatch(changes []FieldChange, test bool) []byte {
+	var buf Buffer
+	WriteJSONPatch(&buf, changes, test)
+	return buf.Bytes()
+}
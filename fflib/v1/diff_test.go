@@ -0,0 +1,48 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestJSONPatch(t *testing.T) {
+	changes := []FieldChange{
+		NewFieldChange("/name", []byte(`"a"`), []byte(`"b"`)),
+		NewFieldChange("/zip", nil, []byte(`1`)),
+	}
+	changes = AppendFieldChanges(changes, "/address", []FieldChange{
+		NewFieldChange("/city", []byte(`"x"`), []byte{}),
+	})
+
+	expected := `[{"op":"replace","path":"/name","value":"b"},` +
+		`{"op":"add","path":"/zip","value":1},` +
+		`{"op":"remove","path":"/address/city"}]`
+	if string(JSONPatch(changes, false)) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(JSONPatch(changes, false)))
+	}
+
+	expected = `[{"op":"test","path":"/name","value":"a"},{"op":"replace","path":"/name","value":"b"}]`
+	if string(JSONPatch(changes[:1], true)) != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, string(JSONPatch(changes[:1], true)))
+	}
+
+	if string(JSONPatch(nil, false)) != `[]` {
+		t.Fatalf("Expected empty patch, got: %v", string(JSONPatch(nil, false)))
+	}
+}
@@ -0,0 +1,47 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// DuplicateKeys selects how generated decoders handle a key appearing
+// twice in the object of a struct.
+type DuplicateKeys uint8
+
+const (
+	// DuplicateKeysAllowed lets the last value win, like encoding/json.
+	DuplicateKeysAllowed DuplicateKeys = iota
+	// DuplicateKeysKnown rejects a field of the struct set twice. Keys
+	// matching a field case-insensitively count as that field.
+	DuplicateKeysKnown
+	// DuplicateKeysAll also rejects unknown keys appearing twice, and
+	// keys repeated in objects decoded into maps and interface{}.
+	DuplicateKeysAll
+)
+
+// DuplicateKeyError is returned by generated decoders for a key appearing
+// twice, see FFLexer.DuplicateKeys.
+type DuplicateKeyError struct {
+	Key string // the second occurrence of the key
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("ffjson: duplicate key %q", e.Key)
+}
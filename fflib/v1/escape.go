@@ -0,0 +1,93 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Escape is a set of options changing how WriteJson escapes strings. It is
+// held by the buffer written to, see Buffer.SetEscape, so generated
+// MarshalJSONBuf methods follow it. The zero value escapes like
+// encoding/json.
+type Escape uint8
+
+const (
+	// EscapeNoHTML leaves <, > and & unescaped, like
+	// json.Encoder.SetEscapeHTML(false).
+	EscapeNoHTML Escape = 1 << iota
+
+	// EscapeASCII writes every non-ASCII character as \uXXXX, with a
+	// surrogate pair outside of the Basic Multilingual Plane, so the output
+	// is plain ASCII.
+	EscapeASCII
+)
+
+// escaper is implemented by buffers holding an Escape.
+type escaper interface {
+	Escape() Escape
+}
+
+func escapeOf(buf JsonStringWriter) Escape {
+	if e, ok := buf.(escaper); ok {
+		return e.Escape()
+	}
+	return 0
+}
+
+// WriteASCII writes the encoded JSON in b, escaping every non-ASCII
+// character as with EscapeASCII. Invalid UTF-8 is written as �.
+func WriteASCII(buf JsonStringWriter, b []byte) {
+	start := 0
+	for i := 0; i < len(b); {
+		if b[i] < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if start < i {
+			buf.Write(b[start:i])
+		}
+		c, size := utf8.DecodeRune(b[i:])
+		writeRuneEscape(buf, c)
+		i += size
+		start = i
+	}
+	if start < len(b) {
+		buf.Write(b[start:])
+	}
+}
+
+// writeRuneEscape writes c as \uXXXX, or as a surrogate pair.
+func writeRuneEscape(buf JsonStringWriter, c rune) {
+	if c >= 0x10000 {
+		r1, r2 := utf16.EncodeRune(c)
+		writeU4(buf, r1)
+		writeU4(buf, r2)
+		return
+	}
+	writeU4(buf, c)
+}
+
+func writeU4(buf JsonStringWriter, c rune) {
+	buf.WriteString(`\u`)
+	buf.WriteByte(hex[c>>12&0xF])
+	buf.WriteByte(hex[c>>8&0xF])
+	buf.WriteByte(hex[c>>4&0xF])
+	buf.WriteByte(hex[c&0xF])
+}
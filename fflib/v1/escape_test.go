@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	s := "<a&b> \u00e9\u2028\U0001F600"
+	tests := []struct {
+		esc      Escape
+		expected string
+	}{
+		{0, "\"\\u003ca\\u0026b\\u003e \u00e9\\u2028\U0001F600\""},
+		{EscapeNoHTML, "\"<a&b> \u00e9\\u2028\U0001F600\""},
+		{EscapeASCII, `"\u003ca\u0026b\u003e \u00e9\u2028\ud83d\ude00"`},
+		{EscapeNoHTML | EscapeASCII, `"<a&b> \u00e9\u2028\ud83d\ude00"`},
+	}
+
+	for _, test := range tests {
+		var buf Buffer
+		buf.SetEscape(test.esc)
+		WriteJsonString(&buf, s)
+		if buf.String() != test.expected {
+			t.Fatalf("Expected: %v\nGot: %v", test.expected, buf.String())
+		}
+
+		buf.Reset()
+		buf.Encode(map[string]string{"k": s})
+		expected := `{"k":` + test.expected + `}`
+		if buf.String() != expected {
+			t.Fatalf("Expected Encode: %v\nGot: %v", expected, buf.String())
+		}
+	}
+
+	var buf Buffer
+	buf.SetEscape(EscapeASCII)
+	WriteJsonString(&buf, "\xff")
+	WriteASCII(&buf, []byte("\xff"))
+	if buf.String() != `"\ufffd"\ufffd` {
+		t.Fatalf("Expected invalid UTF-8 as \\ufffd, got: %v", buf.String())
+	}
+}
@@ -0,0 +1,64 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"strings"
+)
+
+// FieldSet selects fields by JSON name for the generated MarshalJSONFields.
+// A name mapping to nil selects the whole field; a name mapping to a
+// FieldSet selects only those fields of a nested generated type.
+type FieldSet map[string]FieldSet
+
+// NewFieldSet builds a FieldSet from JSON names, with nested fields joined
+// by dots, as in NewFieldSet("id", "name", "owner.name"). Selecting a whole
+// field wins over selecting some of its nested fields. Empty paths are
+// ignored.
+func NewFieldSet(paths ...string) FieldSet {
+	fs := make(FieldSet, len(paths))
+	for _, p := range paths {
+		if p != "" {
+			fs.add(strings.Split(p, "."))
+		}
+	}
+	return fs
+}
+
+func (fs FieldSet) add(names []string) {
+	name := names[0]
+	sub, ok := fs[name]
+	if len(names) == 1 {
+		fs[name] = nil
+		return
+	}
+	if ok && sub == nil {
+		return
+	}
+	if sub == nil {
+		sub = make(FieldSet)
+		fs[name] = sub
+	}
+	sub.add(names[1:])
+}
+
+// Has reports if the field called name is selected, in whole or in part.
+func (fs FieldSet) Has(name string) bool {
+	_, ok := fs[name]
+	return ok
+}
@@ -0,0 +1,41 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewFieldSet(t *testing.T) {
+	fs := NewFieldSet("id", "", "owner.name", "owner.address.city", "items.sku", "items")
+	expected := FieldSet{
+		"id": nil,
+		"owner": FieldSet{
+			"name":    nil,
+			"address": FieldSet{"city": nil},
+		},
+		"items": nil,
+	}
+	if !reflect.DeepEqual(fs, expected) {
+		t.Fatalf("Expected: %v\nGot: %v", expected, fs)
+	}
+	if !fs.Has("owner") || fs.Has("name") {
+		t.Fatalf("Unexpected Has results for %v", fs)
+	}
+}
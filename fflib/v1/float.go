@@ -0,0 +1,82 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// WriteFloat writes f as a JSON number exactly like encoding/json does,
+// with bitSize 32 for float32 values: plain decimals between 1e-6 and
+// 1e21, exponents otherwise. NaN and infinities return a
+// *json.UnsupportedValueError, as from json.Marshal.
+func WriteFloat(buf EncodingBuffer, f float64, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return unsupportedFloat(f, bitSize)
+	}
+
+	// strconv formats float32 values with their shortest representation,
+	// as encoding/json does.
+	fmt := byte('f')
+	if abs := math.Abs(f); abs != 0 && useExpFloat(abs, bitSize) {
+		fmt = 'e'
+	}
+	var scratch [32]byte
+	b := strconv.AppendFloat(scratch[:0], f, fmt, -1, bitSize)
+	if fmt == 'e' {
+		// Exponents drop their leading zeros: 1e-7, not 1e-07.
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// WriteFixedFloat writes f as a JSON number with prec digits after the
+// decimal point, like strconv.FormatFloat(f, 'f', prec, bitSize). NaN and
+// infinities return a *json.UnsupportedValueError.
+func WriteFixedFloat(buf EncodingBuffer, f float64, prec int, bitSize int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return unsupportedFloat(f, bitSize)
+	}
+	AppendFloat(buf, f, 'f', prec, bitSize)
+	return nil
+}
+
+func useExpFloat(abs float64, bitSize int) bool {
+	if bitSize == 32 {
+		return float32(abs) < 1e-6 || float32(abs) >= 1e21
+	}
+	return abs < 1e-6 || abs >= 1e21
+}
+
+func unsupportedFloat(f float64, bitSize int) error {
+	var v interface{} = f
+	if bitSize == 32 {
+		v = float32(f)
+	}
+	return &json.UnsupportedValueError{
+		Value: reflect.ValueOf(v),
+		Str:   strconv.FormatFloat(f, 'g', -1, bitSize),
+	}
+}
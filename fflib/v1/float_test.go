@@ -0,0 +1,116 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWriteFloat(t *testing.T) {
+	values := []float64{
+		0, math.Copysign(0, -1), 1, -1.5, 0.1, 1e-6, 9.99e-7, 1e-7, 123456789.125,
+		1e20, 1e21, 1.5e300, -2.5e-300, 5e-324, math.MaxFloat64, 100, 1e6,
+		float64(float32(1.03226797e+09)),
+	}
+
+	for _, f := range values {
+		var buf Buffer
+		if err := WriteFloat(&buf, f, 64); err != nil {
+			t.Fatalf("WriteFloat(%v): %v", f, err)
+		}
+		expected, _ := json.Marshal(f)
+		if buf.String() != string(expected) {
+			t.Fatalf("Expected: %v\nGot: %v", string(expected), buf.String())
+		}
+
+		buf.Reset()
+		if err := WriteFloat(&buf, float64(float32(f)), 32); err != nil {
+			if !math.IsInf(float64(float32(f)), 0) {
+				t.Fatalf("WriteFloat(float32(%v)): %v", f, err)
+			}
+			continue
+		}
+		expected, _ = json.Marshal(float32(f))
+		if buf.String() != string(expected) {
+			t.Fatalf("Expected float32: %v\nGot: %v", string(expected), buf.String())
+		}
+	}
+
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var buf Buffer
+		err := WriteFloat(&buf, f, 64)
+		if _, ok := err.(*json.UnsupportedValueError); !ok {
+			t.Fatalf("Expected *json.UnsupportedValueError for %v, got: %v", f, err)
+		}
+		_, expected := json.Marshal(f)
+		if err.Error() != expected.Error() {
+			t.Fatalf("Expected: %v\nGot: %v", expected, err)
+		}
+	}
+}
+
+func TestWriteFixedFloat(t *testing.T) {
+	var buf Buffer
+	WriteFixedFloat(&buf, 12.5, 2, 64)
+	buf.WriteByte(' ')
+	WriteFixedFloat(&buf, -0.125, 1, 64)
+	buf.WriteByte(' ')
+	WriteFixedFloat(&buf, 2.5, 0, 32)
+	if buf.String() != "12.50 -0.1 2" {
+		t.Fatalf("Expected fixed decimals, got: %v", buf.String())
+	}
+
+	if _, ok := WriteFixedFloat(&buf, math.Inf(1), 2, 64).(*json.UnsupportedValueError); !ok {
+		t.Fatalf("Expected *json.UnsupportedValueError for +Inf")
+	}
+}
+
+func TestWriteFloatRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var buf Buffer
+	for i := 0; i < 100000; i++ {
+		f32 := math.Float32frombits(r.Uint32())
+		if math.IsNaN(float64(f32)) || math.IsInf(float64(f32), 0) {
+			continue
+		}
+		buf.Reset()
+		if err := WriteFloat(&buf, float64(f32), 32); err != nil {
+			t.Fatalf("WriteFloat(float32(%v)): %v", f32, err)
+		}
+		expected, _ := json.Marshal(f32)
+		if buf.String() != string(expected) {
+			t.Fatalf("Expected float32: %v\nGot: %v", string(expected), buf.String())
+		}
+
+		f64 := math.Float64frombits(r.Uint64())
+		if math.IsNaN(f64) || math.IsInf(f64, 0) {
+			continue
+		}
+		buf.Reset()
+		if err := WriteFloat(&buf, f64, 64); err != nil {
+			t.Fatalf("WriteFloat(%v): %v", f64, err)
+		}
+		expected, _ = json.Marshal(f64)
+		if buf.String() != string(expected) {
+			t.Fatalf("Expected: %v\nGot: %v", string(expected), buf.String())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPathNotFound is returned by Get when the path does not lead to a value.
+var ErrPathNotFound = errors.New("ffjson: path not found")
+
+// Get returns the value at path in the JSON document data, without
+// decoding anything else: object members are selected by key and array
+// elements by decimal index, as in Get(data, "order", "items", "0", "sku").
+// Scanning stops once the value is read, so the rest of data is not
+// checked.
+//
+// tok is the kind of the value. Strings are returned unescaped, other
+// scalars as written, and objects and arrays as their raw JSON text.
+func Get(data []byte, path ...string) (value []byte, tok FFTok, err error) {
+	fs := NewFFLexer(data)
+	tok = fs.Scan()
+	for _, key := range path {
+		switch tok {
+		case FFTok_left_bracket:
+			tok, err = getMember(fs, key)
+		case FFTok_left_brace:
+			tok, err = getElement(fs, key)
+		case FFTok_error, FFTok_eof:
+			err = interfaceTokenError(fs, tok)
+		default:
+			err = ErrPathNotFound
+		}
+		if err != nil {
+			return nil, tok, err
+		}
+	}
+
+	switch tok {
+	case FFTok_string, FFTok_integer, FFTok_double, FFTok_bool, FFTok_null:
+		return fs.Output.Bytes(), tok, nil
+	case FFTok_left_bracket, FFTok_left_brace:
+		value, err = fs.CaptureField(tok)
+		return value, tok, err
+	}
+	return nil, tok, interfaceTokenError(fs, tok)
+}
+
+// getMember scans the object being read up to the value of key, and
+// returns the first token of that value.
+func getMember(fs *FFLexer, key string) (FFTok, error) {
+	tok := fs.Scan()
+	if tok == FFTok_right_bracket {
+		return tok, ErrPathNotFound
+	}
+	for {
+		if tok != FFTok_string {
+			return tok, interfaceTokenError(fs, tok)
+		}
+		found := string(fs.Output.Bytes()) == key
+
+		tok = fs.Scan()
+		if tok != FFTok_colon {
+			return tok, interfaceTokenError(fs, tok)
+		}
+
+		tok = fs.Scan()
+		if found {
+			return tok, nil
+		}
+		if err := fs.SkipField(tok); err != nil {
+			return tok, err
+		}
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_right_bracket:
+			return tok, ErrPathNotFound
+		case FFTok_comma:
+			tok = fs.Scan()
+		default:
+			return tok, interfaceTokenError(fs, tok)
+		}
+	}
+}
+
+// getElement scans the array being read up to the element at index, and
+// returns the first token of that element.
+func getElement(fs *FFLexer, index string) (FFTok, error) {
+	n, err := strconv.Atoi(index)
+	if err != nil || n < 0 {
+		return FFTok_left_brace, ErrPathNotFound
+	}
+
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		return tok, ErrPathNotFound
+	}
+	for i := 0; ; i++ {
+		if i == n {
+			return tok, nil
+		}
+		if err := fs.SkipField(tok); err != nil {
+			return tok, err
+		}
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_right_brace:
+			return tok, ErrPathNotFound
+		case FFTok_comma:
+			tok = fs.Scan()
+		default:
+			return tok, interfaceTokenError(fs, tok)
+		}
+	}
+}
+
+// GetString returns the string at path, see Get.
+func GetString(data []byte, path ...string) (string, error) {
+	v, tok, err := Get(data, path...)
+	if err != nil {
+		return "", err
+	}
+	if tok != FFTok_string {
+		return "", getTypeError(path, tok, "a string")
+	}
+	return string(v), nil
+}
+
+// GetInt64 returns the integer at path, see Get.
+func GetInt64(data []byte, path ...string) (int64, error) {
+	v, tok, err := Get(data, path...)
+	if err != nil {
+		return 0, err
+	}
+	if tok != FFTok_integer {
+		return 0, getTypeError(path, tok, "an integer")
+	}
+	n, err := ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// GetBool returns the boolean at path, see Get.
+func GetBool(data []byte, path ...string) (bool, error) {
+	v, tok, err := Get(data, path...)
+	if err != nil {
+		return false, err
+	}
+	if tok != FFTok_bool {
+		return false, getTypeError(path, tok, "a boolean")
+	}
+	return v[0] == 't', nil
+}
+
+func getTypeError(path []string, tok FFTok, want string) error {
+	return fmt.Errorf("ffjson: value at %q is %v, not %s", strings.Join(path, "."), tok, want)
+}
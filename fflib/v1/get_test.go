@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	data := []byte(`{"order":{"skip":{"x":[1,{"y":2}]},"customer":{"id":42,"name":"li\nsi","vip":true},
+		"items":[{"sku":"a"},{"sku":"b","tags":[ "p", "q" ]}]}} trailing garbage`)
+
+	tests := []struct {
+		path     []string
+		tok      FFTok
+		expected string
+	}{
+		{[]string{"order", "customer", "id"}, FFTok_integer, "42"},
+		{[]string{"order", "customer", "name"}, FFTok_string, "li\nsi"},
+		{[]string{"order", "items", "1", "sku"}, FFTok_string, "b"},
+		{[]string{"order", "items", "1", "tags"}, FFTok_left_brace, `[ "p", "q" ]`},
+		{[]string{"order", "skip", "x", "1"}, FFTok_left_bracket, `{"y":2}`},
+	}
+	for _, test := range tests {
+		v, tok, err := Get(data, test.path...)
+		if err != nil || tok != test.tok || string(v) != test.expected {
+			t.Fatalf("Get(%v): %q %v %v, expected %q %v", test.path, v, tok, err, test.expected, test.tok)
+		}
+	}
+
+	for _, path := range [][]string{{"order", "nope"}, {"order", "items", "2"}, {"order", "items", "x"}, {"order", "customer", "id", "z"}} {
+		if _, _, err := Get(data, path...); err != ErrPathNotFound {
+			t.Fatalf("Get(%v): expected ErrPathNotFound, got: %v", path, err)
+		}
+	}
+	if _, _, err := Get([]byte(`{"a":[1,}`), "a", "2"); err == nil || err == ErrPathNotFound {
+		t.Fatalf("Expected a syntax error, got: %v", err)
+	}
+
+	if id, err := GetInt64(data, "order", "customer", "id"); err != nil || id != 42 {
+		t.Fatalf("GetInt64: %v %v", id, err)
+	}
+	for _, big := range []string{`{"n":9223372036854775808}`, `{"n":-9223372036854775809}`} {
+		if n, err := GetInt64([]byte(big), "n"); err == nil || n != 0 {
+			t.Fatalf("GetInt64(%s): expected 0 and an error, got: %v %v", big, n, err)
+		}
+	}
+	if vip, err := GetBool(data, "order", "customer", "vip"); err != nil || !vip {
+		t.Fatalf("GetBool: %v %v", vip, err)
+	}
+	if s, err := GetString(data, "order", "items", "0", "sku"); err != nil || s != "a" {
+		t.Fatalf("GetString: %v %v", s, err)
+	}
+	if _, err := GetString(data, "order", "customer", "id"); err == nil {
+		t.Fatalf("Expected a type error")
+	}
+}
@@ -0,0 +1,122 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// WriteIndent writes the JSON value in data to buf with the layout of
+// json.MarshalIndent: every element on a new line starting with prefix
+// and one copy of indent per level of nesting. The first line has no
+// prefix. Strings are escaped again by WriteJson.
+func WriteIndent(buf EncodingBuffer, data []byte, prefix, indent string) error {
+	fs := NewFFLexer(data)
+	// WriteJson applies the policy of buf to the strings.
+	fs.UTF8 = UTF8Pass
+	if err := writeIndentValue(buf, fs, fs.Scan(), prefix, indent, 0); err != nil {
+		return err
+	}
+	if tok := fs.Scan(); tok != FFTok_eof {
+		return interfaceTokenError(fs, tok)
+	}
+	return nil
+}
+
+func writeIndentValue(buf EncodingBuffer, fs *FFLexer, tok FFTok, prefix, indent string, depth int) error {
+	switch tok {
+	case FFTok_string:
+		WriteJson(buf, fs.Output.Bytes())
+		return nil
+
+	case FFTok_integer, FFTok_double, FFTok_bool, FFTok_null:
+		buf.Write(fs.Output.Bytes())
+		return nil
+
+	case FFTok_left_bracket:
+		buf.WriteByte('{')
+		tok = fs.Scan()
+		if tok == FFTok_right_bracket {
+			buf.WriteByte('}')
+			return nil
+		}
+		for {
+			if tok != FFTok_string {
+				return interfaceTokenError(fs, tok)
+			}
+			writeIndentLine(buf, prefix, indent, depth+1)
+			WriteJson(buf, fs.Output.Bytes())
+
+			tok = fs.Scan()
+			if tok != FFTok_colon {
+				return interfaceTokenError(fs, tok)
+			}
+			buf.WriteString(": ")
+
+			if err := writeIndentValue(buf, fs, fs.Scan(), prefix, indent, depth+1); err != nil {
+				return err
+			}
+
+			tok = fs.Scan()
+			switch tok {
+			case FFTok_right_bracket:
+				writeIndentLine(buf, prefix, indent, depth)
+				buf.WriteByte('}')
+				return nil
+			case FFTok_comma:
+				buf.WriteByte(',')
+				tok = fs.Scan()
+			default:
+				return interfaceTokenError(fs, tok)
+			}
+		}
+
+	case FFTok_left_brace:
+		buf.WriteByte('[')
+		tok = fs.Scan()
+		if tok == FFTok_right_brace {
+			buf.WriteByte(']')
+			return nil
+		}
+		for {
+			writeIndentLine(buf, prefix, indent, depth+1)
+			if err := writeIndentValue(buf, fs, tok, prefix, indent, depth+1); err != nil {
+				return err
+			}
+
+			tok = fs.Scan()
+			switch tok {
+			case FFTok_right_brace:
+				writeIndentLine(buf, prefix, indent, depth)
+				buf.WriteByte(']')
+				return nil
+			case FFTok_comma:
+				buf.WriteByte(',')
+				tok = fs.Scan()
+			default:
+				return interfaceTokenError(fs, tok)
+			}
+		}
+	}
+
+	return interfaceTokenError(fs, tok)
+}
+
+func writeIndentLine(buf EncodingBuffer, prefix, indent string, depth int) {
+	buf.WriteByte('\n')
+	buf.WriteString(prefix)
+	for i := 0; i < depth; i++ {
+		buf.WriteString(indent)
+	}
+}
@@ -0,0 +1,45 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteIndent(t *testing.T) {
+	in := []byte(`{ "a":[1,-2.5e3,{}],"b":{"c":null,"d":[]} ,"e":"x\u003cy\n","f":true,"g":[[{"h":false}]]}`)
+
+	var buf Buffer
+	if err := WriteIndent(&buf, in, ">", "\t"); err != nil {
+		t.Fatalf("WriteIndent: %v", err)
+	}
+	var expected bytes.Buffer
+	json.Indent(&expected, in, ">", "\t")
+	if buf.String() != expected.String() {
+		t.Fatalf("Expected:\n%v\nGot:\n%v", expected.String(), buf.String())
+	}
+
+	for _, bad := range []string{`{"a":1`, `[1 2]`, `{"a" 1}`, `[1] 2`} {
+		buf.Reset()
+		if err := WriteIndent(&buf, []byte(bad), "", "  "); err == nil {
+			t.Fatalf("Expected error for %s, got: %v", bad, buf.String())
+		}
+	}
+}
@@ -0,0 +1,270 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DecodeInterface decodes the value starting with tok into the generic
+// types of encoding/json: map[string]interface{}, []interface{}, string,
+// float64 (json.Number if fs.UseNumber is set), bool and nil.
+func DecodeInterface(fs *FFLexer, tok FFTok) (interface{}, error) {
+	switch tok {
+	case FFTok_null:
+		return nil, nil
+
+	case FFTok_bool:
+		return fs.Output.Bytes()[0] == 't', nil
+
+	case FFTok_string:
+		return fs.Output.String(), nil
+
+	case FFTok_integer, FFTok_double:
+		if fs.UseNumber {
+			return json.Number(fs.Output.String()), nil
+		}
+		f, err := ParseFloat(fs.Output.Bytes(), 64)
+		if err != nil {
+			return nil, fs.WrapErr(err)
+		}
+		return f, nil
+
+	case FFTok_left_bracket:
+		return decodeInterfaceObject(fs)
+
+	case FFTok_left_brace:
+		return decodeInterfaceArray(fs)
+	}
+
+	return nil, interfaceTokenError(fs, tok)
+}
+
+func decodeInterfaceObject(fs *FFLexer) (interface{}, error) {
+	m := make(map[string]interface{})
+
+	tok := fs.Scan()
+	if tok == FFTok_right_bracket {
+		return m, nil
+	}
+
+	for n := 1; ; n++ {
+		if err := fs.LimitElements(n); err != nil {
+			return nil, fs.WrapErr(err)
+		}
+		if tok != FFTok_string {
+			return nil, interfaceTokenError(fs, tok)
+		}
+		key := fs.Output.String()
+		if fs.DuplicateKeys == DuplicateKeysAll {
+			if _, dup := m[key]; dup {
+				return nil, fs.WrapErr(&DuplicateKeyError{Key: key})
+			}
+		}
+
+		tok = fs.Scan()
+		if tok != FFTok_colon {
+			return nil, interfaceTokenError(fs, tok)
+		}
+
+		v, err := DecodeInterface(fs, fs.Scan())
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_right_bracket:
+			return m, nil
+		case FFTok_comma:
+			tok = fs.Scan()
+		default:
+			return nil, interfaceTokenError(fs, tok)
+		}
+	}
+}
+
+func decodeInterfaceArray(fs *FFLexer) (interface{}, error) {
+	a := make([]interface{}, 0)
+
+	tok := fs.Scan()
+	if tok == FFTok_right_brace {
+		return a, nil
+	}
+
+	for {
+		if err := fs.LimitElements(len(a) + 1); err != nil {
+			return nil, fs.WrapErr(err)
+		}
+		v, err := DecodeInterface(fs, tok)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+
+		tok = fs.Scan()
+		switch tok {
+		case FFTok_right_brace:
+			return a, nil
+		case FFTok_comma:
+			tok = fs.Scan()
+		default:
+			return nil, interfaceTokenError(fs, tok)
+		}
+	}
+}
+
+func interfaceTokenError(fs *FFLexer, tok FFTok) error {
+	switch tok {
+	case FFTok_error:
+		if fs.BigError != nil {
+			return fs.WrapErr(fs.BigError)
+		}
+		if err := fs.Error.ToError(); err != nil {
+			return fs.WrapErr(err)
+		}
+	case FFTok_eof:
+		return fs.WrapErr(errors.New("ffjson: unexpected EOF"))
+	}
+	return fs.WrapErr(fmt.Errorf("ffjson: unexpected token: %v", tok))
+}
+
+// EncodeInterface writes v, which is usually a value decoded by
+// DecodeInterface, without reflection for the generic types of
+// encoding/json. Object keys are sorted, as in encoding/json. Other types
+// are written with buf.Encode.
+func EncodeInterface(buf EncodingBuffer, v interface{}) error {
+	switch tv := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		WriteJsonString(buf, tv)
+	case float64:
+		return WriteFloat(buf, tv, 64)
+	case bool:
+		if tv {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		if tv == "" {
+			// the zero value, written as 0 like encoding/json
+			buf.WriteByte('0')
+		} else if !validNumber(string(tv)) {
+			return fmt.Errorf("ffjson: invalid number literal %q", string(tv))
+		} else {
+			buf.WriteString(string(tv))
+		}
+	case map[string]interface{}:
+		if tv == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		ks := GetKeySlice()
+		for k := range tv {
+			ks.Add(k)
+		}
+		ks.Sort()
+
+		buf.WriteByte('{')
+		for i, k := range ks.Names {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			WriteJsonString(buf, k)
+			buf.WriteByte(':')
+			if err := EncodeInterface(buf, tv[k]); err != nil {
+				PutKeySlice(ks)
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		PutKeySlice(ks)
+	case []interface{}:
+		if tv == nil {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i, e := range tv {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := EncodeInterface(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		return buf.Encode(v)
+	}
+	return nil
+}
+
+// validNumber reports whether s is a number literal of RFC 8259, which is
+// all a json.Number other than "" may be written as.
+func validNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	if s[0] == '-' {
+		s = s[1:]
+		if s == "" {
+			return false
+		}
+	}
+
+	switch {
+	case s[0] == '0':
+		s = s[1:]
+	case '1' <= s[0] && s[0] <= '9':
+		s = skipDigits(s[1:])
+	default:
+		return false
+	}
+
+	if len(s) >= 2 && s[0] == '.' && isDigit(s[1]) {
+		s = skipDigits(s[2:])
+	}
+
+	if len(s) >= 2 && (s[0] == 'e' || s[0] == 'E') {
+		s = s[1:]
+		if s[0] == '+' || s[0] == '-' {
+			s = s[1:]
+		}
+		if s == "" || !isDigit(s[0]) {
+			return false
+		}
+		s = skipDigits(s)
+	}
+
+	return s == ""
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func skipDigits(s string) string {
+	for s != "" && isDigit(s[0]) {
+		s = s[1:]
+	}
+	return s
+}
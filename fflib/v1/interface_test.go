@@ -0,0 +1,139 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var interfaceTestvecs = []string{
+	`null`,
+	`true`,
+	`"aé\n"`,
+	`-1.5e3`,
+	`[]`,
+	`{}`,
+	`[1,"x",[null,false],{"a":{}}]`,
+	`{"b":[1,2,{"c":"d"}],"a":null,"e":{"f":0.25}}`,
+}
+
+func TestDecodeInterface(t *testing.T) {
+	for _, v := range interfaceTestvecs {
+		var expected interface{}
+		if err := json.Unmarshal([]byte(v), &expected); err != nil {
+			t.Fatalf("json.Unmarshal(%v): %v", v, err)
+		}
+
+		fs := NewFFLexer([]byte(v))
+		got, err := DecodeInterface(fs, fs.Scan())
+		if err != nil {
+			t.Fatalf("DecodeInterface(%v): %v", v, err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("DecodeInterface(%v): expected %#v, got %#v", v, expected, got)
+		}
+	}
+
+	for _, v := range []string{`{"a"}`, `{"a":1,}`, `[1 2]`, `[1,`, `{1:2}`, `]`} {
+		fs := NewFFLexer([]byte(v))
+		if _, err := DecodeInterface(fs, fs.Scan()); err == nil {
+			t.Fatalf("DecodeInterface(%v): expected error", v)
+		}
+	}
+}
+
+func TestDecodeInterfaceUseNumber(t *testing.T) {
+	fs := NewFFLexer([]byte(`[12345678901234567890,1.5]`))
+	fs.UseNumber = true
+	got, err := DecodeInterface(fs, fs.Scan())
+	if err != nil {
+		t.Fatalf("DecodeInterface: %v", err)
+	}
+	expected := []interface{}{json.Number("12345678901234567890"), json.Number("1.5")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("Expected %#v, got %#v", expected, got)
+	}
+}
+
+func TestDecodeInterfaceDuplicateKeys(t *testing.T) {
+	input := []byte(`[{"k":1,"j":{"k":2}},{"k":1,"k":2}]`)
+	for _, policy := range []DuplicateKeys{DuplicateKeysAllowed, DuplicateKeysKnown} {
+		fs := NewFFLexer(input)
+		fs.DuplicateKeys = policy
+		if _, err := DecodeInterface(fs, fs.Scan()); err != nil {
+			t.Fatalf("DecodeInterface with policy %d: %v", policy, err)
+		}
+	}
+
+	fs := NewFFLexer(input)
+	fs.DuplicateKeys = DuplicateKeysAll
+	_, err := DecodeInterface(fs, fs.Scan())
+	var de *DuplicateKeyError
+	if !errors.As(err, &de) || de.Key != "k" {
+		t.Fatalf("Expected *DuplicateKeyError for k, got: %v", err)
+	}
+}
+
+func TestEncodeInterface(t *testing.T) {
+	for _, v := range interfaceTestvecs {
+		var val interface{}
+		json.Unmarshal([]byte(v), &val)
+		expected, _ := json.Marshal(val)
+
+		var buf Buffer
+		if err := EncodeInterface(&buf, val); err != nil {
+			t.Fatalf("EncodeInterface(%v): %v", v, err)
+		}
+		if buf.String() != string(expected) {
+			t.Fatalf("Expected: %v\nGot: %v", string(expected), buf.String())
+		}
+	}
+
+	var buf Buffer
+	EncodeInterface(&buf, map[string]interface{}{"n": json.Number("7"), "i": 3})
+	if buf.String() != `{"i":3,"n":7}` {
+		t.Fatalf("Expected fallback encoding, got: %v", buf.String())
+	}
+}
+
+func TestEncodeInterfaceNumber(t *testing.T) {
+	var zero Buffer
+	if err := EncodeInterface(&zero, json.Number("")); err != nil || zero.String() != "0" {
+		t.Fatalf("Expected the zero json.Number to be written as 0, got: %q %v", zero.String(), err)
+	}
+
+	for _, n := range []string{"0", "-0", "7", "-12.5", "1e10", "1.5E-3", "12345678901234567890"} {
+		var buf Buffer
+		if err := EncodeInterface(&buf, json.Number(n)); err != nil || buf.String() != n {
+			t.Fatalf("EncodeInterface(%q): %q %v", n, buf.String(), err)
+		}
+	}
+
+	for _, n := range []string{"-", "01", "1.", ".5", "+1", "1e", "1e+", "0x10", "NaN", "1 ", "1,2"} {
+		var buf Buffer
+		if err := EncodeInterface(&buf, json.Number(n)); err == nil {
+			t.Fatalf("Expected an error for %q, got: %q", n, buf.String())
+		}
+		if _, err := json.Marshal(json.Number(n)); err == nil {
+			t.Fatalf("encoding/json accepts %q", n)
+		}
+	}
+}
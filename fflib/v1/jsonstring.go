@@ -46,6 +46,7 @@ func WriteJsonString(buf JsonStringWriter, s string) {
  * Function ported from encoding/json: func (e *encodeState) string(s string) (int, error)
  */
 func WriteJson(buf JsonStringWriter, s []byte) {
+	esc := escapeOf(buf)
 	buf.WriteByte('"')
 	start := 0
 	for i := 0; i < len(s); {
@@ -60,6 +61,10 @@ func WriteJson(buf JsonStringWriter, s []byte) {
 				i++
 				continue
 			}
+			if esc&EscapeNoHTML != 0 && (b == '<' || b == '>' || b == '&') {
+				i++
+				continue
+			}
 
 			if start < i {
 				buf.Write(s[start:i])
@@ -89,6 +94,10 @@ func WriteJson(buf JsonStringWriter, s []byte) {
 		}
 		c, size := utf8.DecodeRune(s[i:])
 		if c == utf8.RuneError && size == 1 {
+			if invalidUTF8(buf, i) == UTF8Pass {
+				i++
+				continue
+			}
 			if start < i {
 				buf.Write(s[start:i])
 			}
@@ -97,6 +106,15 @@ func WriteJson(buf JsonStringWriter, s []byte) {
 			start = i
 			continue
 		}
+		if esc&EscapeASCII != 0 {
+			if start < i {
+				buf.Write(s[start:i])
+			}
+			writeRuneEscape(buf, c)
+			i += size
+			start = i
+			continue
+		}
 		// U+2028 is LINE SEPARATOR.
 		// U+2029 is PARAGRAPH SEPARATOR.
 		// They are both technically valid characters in JSON strings,
@@ -35,9 +35,11 @@
 package v1
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"unicode/utf8"
 )
 
 type FFParseState int
@@ -101,12 +103,56 @@ type FFLexer struct {
 	Token    FFTok
 	Error    FFErr
 	BigError error
+	// UseNumber makes DecodeInterface return numbers as json.Number
+	// instead of float64.
+	UseNumber bool
+	// Include limits generated decoders to the fields it selects; the
+	// other known keys are skipped. Nil decodes every field.
+	Include FieldSet
+	// Strict rejects what RFC 8259 does not allow: comments, \v and \f
+	// as whitespace, and tokens out of place, like trailing or missing
+	// commas and mismatched nesting. Scan checks every token against the
+	// grammar, so decoders reading the tokens need no checks of their own.
+	// Callers check that nothing follows the top-level value with ScanEOF.
+	Strict bool
+	// Relaxed accepts the JSON5-style input of hand-edited files: comments
+	// are skipped, and trailing commas, single-quoted strings, unquoted
+	// keys, a leading UTF-8 BOM, hex integers and numbers with a leading
+	// plus or dot are allowed. Tokens are rewritten as JSON, so decoders
+	// see standard strings and numbers. It must not be combined with Strict.
+	Relaxed bool
+	// Limits bounds the input, against hostile documents. Scan enforces the
+	// size, depth and string limits; element counts are checked by
+	// SkipField, CaptureField and generated decoders with LimitElements.
+	Limits Limits
+	// DuplicateKeys makes generated decoders reject keys appearing twice
+	// in an object, with a *DuplicateKeyError.
+	DuplicateKeys DuplicateKeys
+	// UTF8 selects how invalid UTF-8 in strings is handled. The default
+	// replaces it with U+FFFD, like encoding/json.
+	UTF8 UTF8
 	// TODO: convert all of this to an interface
 	lastCurrentChar int
 	captureAll      bool
 	buf             Buffer
+	depth           int
+	// grammar position of a Strict lexer, see checkStrict
+	strictState strictState
+	strictNest  []bool
 }
 
+// strictState is the token a Strict lexer expects next.
+type strictState uint8
+
+const (
+	strictValue      strictState = iota // a value
+	strictFirstValue                    // a value or ']' after '['
+	strictFirstKey                      // a key or '}' after '{'
+	strictKey                           // a key after ',' in an object
+	strictColon                         // ':' after a key
+	strictAfter                         // ',' or the closing token after a value
+)
+
 func NewFFLexer(input []byte) *FFLexer {
 	fl := &FFLexer{
 		Token:  FFTok_init,
@@ -124,8 +170,17 @@ type LexerError struct {
 	line   int
 	char   int
 	err    error
+
+	// copy of the line holding the error, for Snippet
+	src     []byte
+	srcPos  int
+	srcMore bool
 }
 
+// snippetMax is the number of bytes of the line kept by a LexerError on
+// each side of the error.
+const snippetMax = 128
+
 // Reset the Lexer and add new input.
 func (ffl *FFLexer) Reset(input []byte) {
 	ffl.Token = FFTok_init
@@ -133,6 +188,9 @@ func (ffl *FFLexer) Reset(input []byte) {
 	ffl.BigError = nil
 	ffl.reader.Reset(input)
 	ffl.lastCurrentChar = 0
+	ffl.depth = 0
+	ffl.strictState = strictValue
+	ffl.strictNest = ffl.strictNest[:0]
 	ffl.Output.Reset()
 }
 
@@ -142,21 +200,133 @@ func (le *LexerError) Error() string {
 		le.offset, le.line, le.char)
 }
 
+// Unwrap returns the error held by le, like a *DepthLimitError.
+func (le *LexerError) Unwrap() error {
+	return le.err
+}
+
+// Offset returns the byte offset of the error in the input.
+func (le *LexerError) Offset() int { return le.offset }
+
+// Line returns the line of the error, counting from 1.
+func (le *LexerError) Line() int { return le.line }
+
+// Column returns the column of the error in bytes, counting from 1. Error
+// prints the number of bytes before it on the line, one less.
+func (le *LexerError) Column() int { return le.char + 1 }
+
+// Snippet returns the line of the input holding the error with a caret
+// under the column, for showing errors to people:
+//
+//	{"id": 1, "name": tru}
+//	                     ^
+//
+// At most context bytes are shown on each side of the column, and "..."
+// marks cut text. With context <= 0 the whole line is shown, up to 128
+// bytes on each side.
+func (le *LexerError) Snippet(context int) string {
+	if le.src == nil {
+		return ""
+	}
+	line, pos := le.src, le.srcPos
+	left, right := pos < le.char, le.srcMore
+	if context > 0 {
+		if pos > context {
+			i := pos - context
+			for i < pos && !utf8.RuneStart(line[i]) {
+				i++
+			}
+			line, pos, left = line[i:], pos-i, true
+		}
+		if len(line)-pos > context {
+			i := pos + context
+			for i > pos && !utf8.RuneStart(line[i]) {
+				i--
+			}
+			line, right = line[:i], true
+		}
+	}
+
+	var b bytes.Buffer
+	if left {
+		b.WriteString("...")
+	}
+	b.Write(line)
+	if right {
+		b.WriteString("...")
+	}
+	b.WriteByte('\n')
+	if left {
+		b.WriteString("   ")
+	}
+	// Keep tabs so the caret lines up in a terminal.
+	for _, c := range string(line[:pos]) {
+		if c == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	return b.String()
+}
+
+// PassError reports if generated decoders return err as it is, instead of
+// the error message of the field: errors over the Limits, duplicate keys and
+// invalid UTF-8.
+func PassError(err error) bool {
+	if IsLimitError(err) {
+		return true
+	}
+	if le, ok := err.(*LexerError); ok {
+		err = le.err
+	}
+	switch err.(type) {
+	case *DuplicateKeyError, *InvalidUTF8Error:
+		return true
+	}
+	return false
+}
+
+// sub returns a lexer for input, a value read by ffl, with the settings
+// of ffl. The depth is kept too, so Limits.MaxDepth covers the nesting of
+// both lexers.
+func (ffl *FFLexer) sub(input []byte) *FFLexer {
+	fs := NewFFLexer(input)
+	fs.UseNumber = ffl.UseNumber
+	fs.Include = ffl.Include
+	fs.Strict = ffl.Strict
+	fs.Relaxed = ffl.Relaxed
+	fs.Limits = ffl.Limits
+	fs.DuplicateKeys = ffl.DuplicateKeys
+	fs.UTF8 = ffl.UTF8
+	fs.depth = ffl.depth
+	return fs
+}
+
+// WrapErr returns err as a *LexerError at the current position. An error
+// that already is one is returned as it is, keeping the innermost position.
 func (ffl *FFLexer) WrapErr(err error) error {
+	if le, ok := err.(*LexerError); ok {
+		return le
+	}
 	line, char := ffl.reader.PosWithLine()
-	// TOOD: calcualte lines/characters based on offset
+	src, pos, more := ffl.reader.lineAround(snippetMax)
 	return &LexerError{
-		offset: ffl.reader.Pos(),
-		line:   line,
-		char:   char,
-		err:    err,
+		offset:  ffl.reader.Pos(),
+		line:    line,
+		char:    char,
+		err:     err,
+		src:     src,
+		srcPos:  pos,
+		srcMore: more,
 	}
 }
 
 func (ffl *FFLexer) scanReadByte() (byte, error) {
 	var c byte
 	var err error
-	if ffl.captureAll {
+	if ffl.captureAll || ffl.Strict {
 		c, err = ffl.reader.ReadByte()
 	} else {
 		c, err = ffl.reader.ReadByteNoWS()
@@ -213,18 +383,14 @@ func (ffl *FFLexer) wantBytes(want []byte, iftrue FFTok) FFTok {
 func (ffl *FFLexer) lexComment() FFTok {
 	c, err := ffl.readByte()
 	if err != nil {
-		return FFTok_error
+		return ffl.incompleteComment()
 	}
 
 	if c == '/' {
-		// a // comment, scan until line ends.
+		// a // comment, scan until line or input ends.
 		for {
-			c, err := ffl.readByte()
-			if err != nil {
-				return FFTok_error
-			}
-
-			if c == '\n' {
+			c, more := ffl.readNumByte()
+			if !more || c == '\n' {
 				return FFTok_comment
 			}
 		}
@@ -233,22 +399,18 @@ func (ffl *FFLexer) lexComment() FFTok {
 		for {
 			c, err := ffl.readByte()
 			if err != nil {
-				return FFTok_error
+				return ffl.incompleteComment()
 			}
 
-			if c == '*' {
-				c, err := ffl.readByte()
-
+			for c == '*' {
+				c, err = ffl.readByte()
 				if err != nil {
-					return FFTok_error
+					return ffl.incompleteComment()
 				}
 
 				if c == '/' {
 					return FFTok_comment
 				}
-
-				ffl.Error = FFErr_incomplete_comment
-				return FFTok_error
 			}
 		}
 	} else {
@@ -257,6 +419,14 @@ func (ffl *FFLexer) lexComment() FFTok {
 	}
 }
 
+// incompleteComment reports a comment cut off by the end of the input,
+// instead of the io.EOF left by readByte.
+func (ffl *FFLexer) incompleteComment() FFTok {
+	ffl.Error = FFErr_incomplete_comment
+	ffl.BigError = nil
+	return FFTok_error
+}
+
 func (ffl *FFLexer) lexString() FFTok {
 	if ffl.captureAll {
 		ffl.buf.Reset()
@@ -267,6 +437,10 @@ func (ffl *FFLexer) lexString() FFTok {
 			return FFTok_error
 		}
 
+		if !ffl.limitString(ffl.buf.Len()) {
+			return FFTok_error
+		}
+
 		WriteJson(ffl.Output, ffl.buf.Bytes())
 
 		return FFTok_string
@@ -278,64 +452,168 @@ func (ffl *FFLexer) lexString() FFTok {
 			return FFTok_error
 		}
 
+		if !ffl.limitString(ffl.Output.Len()) {
+			return FFTok_error
+		}
+
+		return FFTok_string
+	}
+}
+
+// lexSingleQuoted is lexString for the single-quoted strings of the
+// relaxed mode.
+func (ffl *FFLexer) lexSingleQuoted() FFTok {
+	out := ffl.Output
+	if ffl.captureAll {
+		ffl.buf.Reset()
+		out = &ffl.buf
+	}
+
+	err := ffl.reader.SliceSingleQuoted(out)
+	if err != nil {
+		ffl.BigError = err
+		return FFTok_error
+	}
+
+	if !ffl.limitString(out.Len()) {
+		return FFTok_error
+	}
+
+	if ffl.captureAll {
+		WriteJson(ffl.Output, ffl.buf.Bytes())
+	}
+	return FFTok_string
+}
+
+func isIdentByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$'
+}
+
+// lexIdent lexes an identifier of the relaxed mode, starting with the
+// byte just read. Followed by a colon it is an unquoted key, otherwise it
+// must be true, false or null.
+func (ffl *FFLexer) lexIdent() FFTok {
+	startPos := ffl.reader.Pos() - 1
+	for {
+		c, more := ffl.readNumByte()
+		if !more {
+			break
+		}
+		if !isIdentByte(c) {
+			ffl.unreadByte()
+			break
+		}
+	}
+	ident := ffl.reader.Slice(startPos, ffl.reader.Pos())
+
+	if c, ok := ffl.reader.PeekNoWS(); ok && c == ':' {
+		if ffl.captureAll {
+			WriteJson(ffl.Output, ident)
+		} else {
+			ffl.Output.Write(ident)
+		}
 		return FFTok_string
 	}
+
+	switch string(ident) {
+	case "true", "false":
+		ffl.Output.Write(ident)
+		return FFTok_bool
+	case "null":
+		ffl.Output.Write(ident)
+		return FFTok_null
+	}
+	ffl.Error = FFErr_invalid_string
+	return FFTok_error
+}
+
+// skipRelaxed skips whitespace and comments in relaxed mode. It returns
+// FFTok_error on a broken comment, FFTok_init otherwise.
+func (ffl *FFLexer) skipRelaxed() FFTok {
+	for {
+		c, more := ffl.readNumByte()
+		if !more {
+			return FFTok_init
+		}
+		switch c {
+		case '\t', '\n', '\v', '\f', '\r', ' ':
+		case '/':
+			if tok := ffl.lexComment(); tok == FFTok_error {
+				return tok
+			}
+		default:
+			ffl.unreadByte()
+			return FFTok_init
+		}
+	}
+}
+
+// readNumByte is readByte for lexNumber: a number may end the input,
+// so EOF is reported as more=false instead of an error.
+func (ffl *FFLexer) readNumByte() (c byte, more bool) {
+	c, err := ffl.reader.ReadByte()
+	if err != nil {
+		return 0, false
+	}
+	return c, true
 }
 
 func (ffl *FFLexer) lexNumber() FFTok {
 	var numRead int = 0
 	tok := FFTok_integer
 	startPos := ffl.reader.Pos()
+	// dotPos is the position of a leading dot in relaxed mode, which is
+	// written as "0.".
+	dotPos := -1
 
 	c, err := ffl.readByte()
 	if err != nil {
 		return FFTok_error
 	}
+	more := true
 
 	/* optional leading minus */
 	if c == '-' {
-		c, err = ffl.readByte()
-		if err != nil {
-			return FFTok_error
-		}
+		c, more = ffl.readNumByte()
+	} else if c == '+' && ffl.Relaxed {
+		// JSON has no plus sign, leave it out
+		startPos++
+		c, more = ffl.readNumByte()
 	}
 
 	/* a single zero, or a series of integers */
 	if c == '0' {
-		c, err = ffl.readByte()
-		if err != nil {
-			return FFTok_error
+		c, more = ffl.readNumByte()
+		if (c == 'x' || c == 'X') && ffl.Relaxed {
+			return ffl.lexHex(startPos)
 		}
 	} else if c >= '1' && c <= '9' {
 		for c >= '0' && c <= '9' {
-			c, err = ffl.readByte()
-			if err != nil {
-				return FFTok_error
-			}
+			c, more = ffl.readNumByte()
 		}
+	} else if c == '.' && ffl.Relaxed {
+		dotPos = ffl.reader.Pos() - 1
 	} else {
-		ffl.unreadByte()
+		if more {
+			ffl.unreadByte()
+		}
 		ffl.Error = FFErr_missing_integer_after_minus
 		return FFTok_error
 	}
 
 	if c == '.' {
 		numRead = 0
-		c, err = ffl.readByte()
-		if err != nil {
-			return FFTok_error
-		}
+		c, more = ffl.readNumByte()
 
 		for c >= '0' && c <= '9' {
 			numRead++
-			c, err = ffl.readByte()
-			if err != nil {
-				return FFTok_error
-			}
+			c, more = ffl.readNumByte()
 		}
 
 		if numRead == 0 {
-			ffl.unreadByte()
+			if more {
+				ffl.unreadByte()
+			}
 
 			ffl.Error = FFErr_missing_integer_after_decimal
 			return FFTok_error
@@ -347,25 +625,16 @@ func (ffl *FFLexer) lexNumber() FFTok {
 	/* optional exponent (indicates this is floating point) */
 	if c == 'e' || c == 'E' {
 		numRead = 0
-		c, err = ffl.readByte()
-		if err != nil {
-			return FFTok_error
-		}
+		c, more = ffl.readNumByte()
 
 		/* optional sign */
 		if c == '+' || c == '-' {
-			c, err = ffl.readByte()
-			if err != nil {
-				return FFTok_error
-			}
+			c, more = ffl.readNumByte()
 		}
 
 		for c >= '0' && c <= '9' {
 			numRead++
-			c, err = ffl.readByte()
-			if err != nil {
-				return FFTok_error
-			}
+			c, more = ffl.readNumByte()
 		}
 
 		if numRead == 0 {
@@ -376,13 +645,69 @@ func (ffl *FFLexer) lexNumber() FFTok {
 		tok = FFTok_double
 	}
 
-	ffl.unreadByte()
+	if more {
+		ffl.unreadByte()
+		if ffl.Relaxed && followsNumber(c) {
+			ffl.Error = FFErr_invalid_char
+			return FFTok_error
+		}
+	}
 
 	endPos := ffl.reader.Pos()
+	if dotPos >= 0 {
+		ffl.Output.Write(ffl.reader.Slice(startPos, dotPos))
+		ffl.Output.WriteByte('0')
+		startPos = dotPos
+	}
 	ffl.Output.Write(ffl.reader.Slice(startPos, endPos))
 	return tok
 }
 
+// followsNumber reports if c, directly after a number of the relaxed mode,
+// would be lexed as part of the next token, as in 1a or 1+2. These tokens
+// would be written next to each other as something else.
+func followsNumber(c byte) bool {
+	return isIdentByte(c) || c == '.' || c == '+' || c == '-'
+}
+
+// lexHex lexes the digits of a hex integer of the relaxed mode, which
+// starts at startPos and has been read up to its 0x. Output holds the
+// value in decimal.
+func (ffl *FFLexer) lexHex(startPos int) FFTok {
+	hexPos := ffl.reader.Pos()
+	for {
+		c, more := ffl.readNumByte()
+		if !more {
+			break
+		}
+		if byteLookupTable[c]&cVHC == 0 {
+			ffl.unreadByte()
+			if followsNumber(c) {
+				ffl.Error = FFErr_invalid_char
+				return FFTok_error
+			}
+			break
+		}
+	}
+
+	digits := ffl.reader.Slice(hexPos, ffl.reader.Pos())
+	if len(digits) == 0 {
+		ffl.Error = FFErr_invalid_char
+		return FFTok_error
+	}
+	n, err := ParseUint(digits, 16, 64)
+	if err != nil {
+		ffl.BigError = err
+		return FFTok_error
+	}
+
+	if ffl.reader.Slice(startPos, hexPos)[0] == '-' {
+		ffl.Output.WriteByte('-')
+	}
+	FormatBits2(ffl.Output, n, 10, false)
+	return FFTok_integer
+}
+
 var true_bytes = []byte{'r', 'u', 'e'}
 var false_bytes = []byte{'a', 'l', 's', 'e'}
 var null_bytes = []byte{'u', 'l', 'l'}
@@ -392,7 +717,17 @@ func (ffl *FFLexer) Scan() FFTok {
 	if ffl.captureAll == false {
 		ffl.Output.Reset()
 	}
+	prev := ffl.Token
 	ffl.Token = FFTok_init
+	ffl.reader.utf8 = ffl.UTF8
+	if ffl.Limits.MaxSize > 0 && ffl.reader.l > ffl.Limits.MaxSize {
+		ffl.BigError = &SizeLimitError{Limit: ffl.Limits.MaxSize}
+		ffl.Token = FFTok_error
+		return FFTok_error
+	}
+	if ffl.Relaxed {
+		ffl.reader.SkipBOM()
+	}
 
 	for {
 		c, err := ffl.scanReadByte()
@@ -407,29 +742,55 @@ func (ffl *FFLexer) Scan() FFTok {
 		switch c {
 		case '{':
 			tok = FFTok_left_bracket
+			if !ffl.enter() {
+				tok = FFTok_error
+				goto lexed
+			}
 			if ffl.captureAll {
 				ffl.Output.WriteByte('{')
 			}
 			goto lexed
 		case '}':
 			tok = FFTok_right_bracket
+			ffl.depth--
 			if ffl.captureAll {
 				ffl.Output.WriteByte('}')
 			}
 			goto lexed
 		case '[':
 			tok = FFTok_left_brace
+			if !ffl.enter() {
+				tok = FFTok_error
+				goto lexed
+			}
 			if ffl.captureAll {
 				ffl.Output.WriteByte('[')
 			}
 			goto lexed
 		case ']':
 			tok = FFTok_right_brace
+			ffl.depth--
 			if ffl.captureAll {
 				ffl.Output.WriteByte(']')
 			}
 			goto lexed
 		case ',':
+			if ffl.Relaxed {
+				if !endsValue(prev) {
+					// a leading or repeated comma, which is not dropped
+					// as a trailing one
+					tok = FFTok_error
+					ffl.Error = FFErr_unexpected_token_type
+					goto lexed
+				}
+				if tok = ffl.skipRelaxed(); tok == FFTok_error {
+					goto lexed
+				}
+				if c, ok := ffl.reader.PeekNoWS(); ok && (c == '}' || c == ']') {
+					// a trailing comma, scan the closing token
+					break
+				}
+			}
 			tok = FFTok_comma
 			if ffl.captureAll {
 				ffl.Output.WriteByte(',')
@@ -441,44 +802,185 @@ func (ffl *FFLexer) Scan() FFTok {
 				ffl.Output.WriteByte(':')
 			}
 			goto lexed
-		case '\t', '\n', '\v', '\f', '\r', ' ':
+		case '\t', '\n', '\r', ' ':
+			if ffl.captureAll {
+				ffl.Output.WriteByte(c)
+			}
+			break
+		case '\v', '\f':
+			if ffl.Strict {
+				tok = FFTok_error
+				ffl.Error = FFErr_invalid_char
+				goto lexed
+			}
 			if ffl.captureAll {
 				ffl.Output.WriteByte(c)
 			}
 			break
 		case 't':
+			if ffl.Relaxed {
+				tok = ffl.lexIdent()
+				goto lexed
+			}
 			ffl.Output.WriteByte('t')
 			tok = ffl.wantBytes(true_bytes, FFTok_bool)
 			goto lexed
 		case 'f':
+			if ffl.Relaxed {
+				tok = ffl.lexIdent()
+				goto lexed
+			}
 			ffl.Output.WriteByte('f')
 			tok = ffl.wantBytes(false_bytes, FFTok_bool)
 			goto lexed
 		case 'n':
+			if ffl.Relaxed {
+				tok = ffl.lexIdent()
+				goto lexed
+			}
 			ffl.Output.WriteByte('n')
 			tok = ffl.wantBytes(null_bytes, FFTok_null)
 			goto lexed
 		case '"':
 			tok = ffl.lexString()
 			goto lexed
+		case '\'':
+			if ffl.Relaxed {
+				tok = ffl.lexSingleQuoted()
+				goto lexed
+			}
+			tok = FFTok_error
+			ffl.Error = FFErr_invalid_char
+			goto lexed
 		case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
 			ffl.unreadByte()
 			tok = ffl.lexNumber()
 			goto lexed
+		case '+', '.':
+			if ffl.Relaxed {
+				ffl.unreadByte()
+				tok = ffl.lexNumber()
+				goto lexed
+			}
+			tok = FFTok_error
+			ffl.Error = FFErr_invalid_char
+			goto lexed
 		case '/':
+			if ffl.Strict {
+				tok = FFTok_error
+				ffl.Error = FFErr_unallowed_comment
+				goto lexed
+			}
 			tok = ffl.lexComment()
+			if tok == FFTok_comment && ffl.Relaxed {
+				break
+			}
 			goto lexed
 		default:
+			if ffl.Relaxed && isIdentByte(c) && (c < '0' || c > '9') {
+				tok = ffl.lexIdent()
+				goto lexed
+			}
 			tok = FFTok_error
 			ffl.Error = FFErr_invalid_char
+			goto lexed
 		}
 	}
 
 lexed:
+	if ffl.Strict && tok != FFTok_error && !ffl.checkStrict(tok) {
+		tok = FFTok_error
+		ffl.Error = FFErr_unexpected_token_type
+	}
 	ffl.Token = tok
 	return tok
 }
 
+// endsValue reports if tok is the last token of a value.
+func endsValue(tok FFTok) bool {
+	switch tok {
+	case FFTok_string, FFTok_integer, FFTok_double, FFTok_bool, FFTok_null,
+		FFTok_right_bracket, FFTok_right_brace:
+		return true
+	}
+	return false
+}
+
+// checkStrict moves a Strict lexer along the RFC 8259 grammar, reporting
+// if tok may appear at this point. Tokens after the top-level value are
+// left to ScanEOF.
+func (ffl *FFLexer) checkStrict(tok FFTok) bool {
+	switch ffl.strictState {
+	case strictFirstKey, strictKey:
+		if tok == FFTok_right_bracket && ffl.strictState == strictFirstKey {
+			return ffl.closeStrict(true)
+		}
+		if tok != FFTok_string {
+			return false
+		}
+		ffl.strictState = strictColon
+		return true
+
+	case strictColon:
+		if tok != FFTok_colon {
+			return false
+		}
+		ffl.strictState = strictValue
+		return true
+
+	case strictAfter:
+		if len(ffl.strictNest) == 0 {
+			return true
+		}
+		object := ffl.strictNest[len(ffl.strictNest)-1]
+		switch tok {
+		case FFTok_comma:
+			if object {
+				ffl.strictState = strictKey
+			} else {
+				ffl.strictState = strictValue
+			}
+			return true
+		case FFTok_right_bracket:
+			return ffl.closeStrict(true)
+		case FFTok_right_brace:
+			return ffl.closeStrict(false)
+		}
+		return false
+
+	case strictFirstValue:
+		if tok == FFTok_right_brace {
+			return ffl.closeStrict(false)
+		}
+	}
+
+	switch tok {
+	case FFTok_string, FFTok_integer, FFTok_double, FFTok_bool, FFTok_null:
+		ffl.strictState = strictAfter
+	case FFTok_left_bracket:
+		ffl.strictNest = append(ffl.strictNest, true)
+		ffl.strictState = strictFirstKey
+	case FFTok_left_brace:
+		ffl.strictNest = append(ffl.strictNest, false)
+		ffl.strictState = strictFirstValue
+	default:
+		return false
+	}
+	return true
+}
+
+// closeStrict closes the innermost container of a Strict lexer if it is
+// an object, or an array.
+func (ffl *FFLexer) closeStrict(object bool) bool {
+	n := len(ffl.strictNest)
+	if n == 0 || ffl.strictNest[n-1] != object {
+		return false
+	}
+	ffl.strictNest = ffl.strictNest[:n-1]
+	ffl.strictState = strictAfter
+	return true
+}
+
 func (ffl *FFLexer) scanField(start FFTok, capture bool) ([]byte, error) {
 	switch start {
 	case FFTok_left_brace,
@@ -497,6 +999,12 @@ func (ffl *FFLexer) scanField(start FFTok, capture bool) ([]byte, error) {
 			}
 
 			depth := 1
+			// With an element limit, counts holds the commas read in each
+			// open container.
+			var counts []int
+			if ffl.Limits.MaxElements > 0 {
+				counts = append(counts, 0)
+			}
 			if capture {
 				ffl.captureAll = true
 			}
@@ -513,13 +1021,37 @@ func (ffl *FFLexer) scanField(start FFTok, capture bool) ([]byte, error) {
 						return nil, ffl.BigError
 					}
 					return nil, ffl.Error.ToError()
-				case end:
-					depth--
-					if depth == 0 {
-						break scanloop
+				case FFTok_comma:
+					if counts != nil {
+						counts[len(counts)-1]++
+						if err := ffl.LimitElements(counts[len(counts)-1] + 1); err != nil {
+							return nil, err
+						}
+					}
+				case FFTok_right_bracket, FFTok_right_brace:
+					if len(counts) > 1 {
+						counts = counts[:len(counts)-1]
+					}
+					if tok == end {
+						depth--
+						if depth == 0 {
+							break scanloop
+						}
+					}
+				case FFTok_left_bracket:
+					if counts != nil {
+						counts = append(counts, 0)
+					}
+					if tok == start {
+						depth++
+					}
+				case FFTok_left_brace:
+					if counts != nil {
+						counts = append(counts, 0)
+					}
+					if tok == start {
+						depth++
 					}
-				case start:
-					depth++
 				}
 			}
 
@@ -572,6 +1104,21 @@ func (ffl *FFLexer) SkipField(start FFTok) error {
 	return err
 }
 
+var errTrailingData = errors.New("ffjson: invalid data after top-level value")
+
+// ScanEOF returns an error unless only whitespace is left in the input.
+// Strict callers use it after the top-level value.
+func (ffl *FFLexer) ScanEOF() error {
+	tok := ffl.Scan()
+	switch tok {
+	case FFTok_eof:
+		return nil
+	case FFTok_error:
+		return interfaceTokenError(ffl, tok)
+	}
+	return ffl.WrapErr(errTrailingData)
+}
+
 // TODO(pquerna): return line number and offset.
 func (err FFErr) ToError() error {
 	switch err {
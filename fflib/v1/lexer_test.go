@@ -19,9 +19,12 @@ package v1
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"testing"
+	"unicode/utf8"
 )
 
 func scanAll(ffl *FFLexer) []FFTok {
@@ -270,6 +273,25 @@ func TestInt(t *testing.T) {
 	tInt(t, `{"a": -0}`, -0)
 }
 
+func TestNumberAtEOF(t *testing.T) {
+	ffl := NewFFLexer([]byte(`-12.5e3`))
+	assertTokensEqual(t, []FFTok{
+		FFTok_double,
+		FFTok_eof,
+	}, scanAll(ffl))
+
+	ffl = NewFFLexer([]byte(`42`))
+	tok := ffl.Scan()
+	if tok != FFTok_integer || ffl.Output.String() != "42" {
+		t.Fatalf("expected integer 42, got %v %v", tok, ffl.Output.String())
+	}
+
+	ffl = NewFFLexer([]byte(`1.`))
+	if tok := ffl.Scan(); tok != FFTok_error || ffl.Error != FFErr_missing_integer_after_decimal {
+		t.Fatalf("expected missing integer after decimal, got %v %v", tok, ffl.Error)
+	}
+}
+
 func tError(t *testing.T, input string, targetCount int, targetError FFErr) {
 	ffl := NewFFLexer([]byte(input))
 	count, err := scanToTokCount(ffl, FFTok_error)
@@ -305,6 +327,37 @@ func TestInvalid(t *testing.T) {
 	tError(t, `{"a": 1.a}`, 4, FFErr_missing_integer_after_decimal)
 }
 
+func TestLexerErrorSnippet(t *testing.T) {
+	ffl := NewFFLexer([]byte("{\n\t\"id\": 1,\r\n\t\"name\": tru}\n"))
+	for ffl.Scan() != FFTok_error {
+	}
+	err := ffl.WrapErr(ffl.Error.ToError()).(*LexerError)
+	if err.Offset() != 25 || err.Line() != 3 || err.Column() != 13 {
+		t.Fatalf("Expected offset 25 at 3:13, got %d at %d:%d", err.Offset(), err.Line(), err.Column())
+	}
+	if s := err.Snippet(0); s != "\t\"name\": tru}\n\t           ^" {
+		t.Fatalf("Snippet(0):\n%s", s)
+	}
+	if s := err.Snippet(4); s != "... tru}\n       ^" {
+		t.Fatalf("Snippet(4):\n%s", s)
+	}
+
+	long := []byte(`["` + strings.Repeat("é", 200) + `", nul, "` + strings.Repeat("x", 200) + `"]`)
+	ffl = NewFFLexer(long)
+	for ffl.Scan() != FFTok_error {
+	}
+	err = ffl.WrapErr(ffl.Error.ToError()).(*LexerError)
+	s := err.Snippet(0)
+	lines := strings.Split(s, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "...") || !strings.HasSuffix(lines[0], "...") ||
+		!utf8.ValidString(lines[0]) || !strings.Contains(lines[0], `", nul,`) {
+		t.Fatalf("Snippet of a long line:\n%s", s)
+	}
+	if caret := utf8.RuneCountInString(lines[1]) - 1; !strings.HasPrefix(string([]rune(lines[0])[caret:]), `, "x`) {
+		t.Fatalf("Caret not under the error:\n%s", s)
+	}
+}
+
 func TestCapture(t *testing.T) {
 	ffl := NewFFLexer([]byte(`{"hello": {"blah": [null, 1]}}`))
 
@@ -327,3 +380,175 @@ func TestCapture(t *testing.T) {
 		t.Fatalf("didnt capture subfield: buf: %v", string(buf))
 	}
 }
+
+func TestStrict(t *testing.T) {
+	ffl := NewFFLexer([]byte(`{"hello": /* comment */ 0}`))
+	ffl.Strict = true
+	assertTokensEqual(t, []FFTok{
+		FFTok_left_bracket,
+		FFTok_string,
+		FFTok_colon,
+		FFTok_error,
+	}, scanAll(ffl))
+	if ffl.Error != FFErr_unallowed_comment {
+		t.Fatalf("Expected FFErr_unallowed_comment, got: %v", ffl.Error)
+	}
+
+	ffl = NewFFLexer([]byte("[1,\v2]"))
+	ffl.Strict = true
+	assertTokensEqual(t, []FFTok{
+		FFTok_left_brace,
+		FFTok_integer,
+		FFTok_comma,
+		FFTok_error,
+	}, scanAll(ffl))
+
+	ffl = NewFFLexer([]byte(`{"a":[}]}`))
+	if err := ffl.SkipField(ffl.Scan()); err != nil {
+		t.Fatalf("SkipField: %v", err)
+	}
+	ffl = NewFFLexer([]byte(`{"a":[}]}`))
+	ffl.Strict = true
+	if err := ffl.SkipField(ffl.Scan()); err == nil {
+		t.Fatalf("Expected an error for mismatched nesting")
+	}
+
+	ffl = NewFFLexer([]byte(`{"a":[{}]} `))
+	ffl.Strict = true
+	buf, err := ffl.CaptureField(ffl.Scan())
+	if err != nil || string(buf) != `{"a":[{}]}` {
+		t.Fatalf("CaptureField: %s %v", buf, err)
+	}
+	if err := ffl.ScanEOF(); err != nil {
+		t.Fatalf("ScanEOF: %v", err)
+	}
+
+	ffl = NewFFLexer([]byte(`{} {}`))
+	ffl.Strict = true
+	ffl.SkipField(ffl.Scan())
+	if err := ffl.ScanEOF(); err == nil {
+		t.Fatalf("Expected an error for trailing data")
+	}
+
+	for _, input := range []string{`[1,]`, `[1 2]`, `{"a":1,}`, `{"a" 1}`, `{1:2}`, `[,1]`} {
+		ffl = NewFFLexer([]byte(input))
+		ffl.Strict = true
+		toks := scanAll(ffl)
+		if toks[len(toks)-1] != FFTok_error || ffl.Error != FFErr_unexpected_token_type {
+			t.Fatalf("Expected %s to be rejected, got: %v %v", input, toks, ffl.Error)
+		}
+	}
+}
+
+func TestRelaxed(t *testing.T) {
+	input := "\xef\xbb\xbf" + `{
+	// a comment
+	name: 'it\'s', /** doc **/
+	"quote": 'say "hi"',
+	'list': [0x1F, -0XfF, +1, .5, -.5e1, true, null,],
+	$key_2: {n: false,},
+}
+// the end`
+
+	ffl := NewFFLexer([]byte(input))
+	ffl.Relaxed = true
+	var got []string
+	for {
+		tok := ffl.Scan()
+		if tok == FFTok_eof {
+			break
+		}
+		if tok == FFTok_error {
+			t.Fatalf("Scan: %v %v", ffl.Error, ffl.BigError)
+		}
+		got = append(got, ffl.Output.String())
+	}
+
+	expected := []string{
+		"", "name", "", `it's`, "", "quote", "", `say "hi"`, "",
+		"list", "", "", "31", "", "-255", "", "1", "", "0.5", "", "-0.5e1", "", "true", "", "null", "", "",
+		"$key_2", "", "", "n", "", "false", "", "",
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("Expected: %q\nGot: %q", expected, got)
+	}
+
+	raw, err := RelaxedToJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("RelaxedToJSON: %v", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		t.Fatalf("RelaxedToJSON output %s: %v", raw, err)
+	}
+
+	for _, bad := range []string{`{a b: 1}`, `[yes]`, `[0x]`, `['a]`, `[.]`, `[1] x`,
+		`[,]`, `{,}`, `[1,,]`, `{a:1,,}`, `{1a:1}`, `{0x1Fg:1}`, `[01]`, `[1+2]`, `[1] /* x`} {
+		if _, err := RelaxedToJSON([]byte(bad)); err == nil {
+			t.Fatalf("Expected an error for: %s", bad)
+		}
+	}
+
+	ffl = NewFFLexer([]byte(`[1,]`))
+	assertTokensEqual(t, []FFTok{
+		FFTok_left_brace,
+		FFTok_integer,
+		FFTok_comma,
+		FFTok_right_brace,
+		FFTok_eof,
+	}, scanAll(ffl))
+
+	// Only a comma after a value is dropped before a closing token.
+	tests := []struct {
+		input    string
+		expected []FFTok
+		err      FFErr
+	}{
+		{`[,]`, []FFTok{FFTok_left_brace, FFTok_error}, FFErr_unexpected_token_type},
+		{`{,}`, []FFTok{FFTok_left_bracket, FFTok_error}, FFErr_unexpected_token_type},
+		{`[1,,]`, []FFTok{FFTok_left_brace, FFTok_integer, FFTok_comma, FFTok_error}, FFErr_unexpected_token_type},
+		{`{a:1,,}`, []FFTok{FFTok_left_bracket, FFTok_string, FFTok_colon, FFTok_integer, FFTok_comma, FFTok_error}, FFErr_unexpected_token_type},
+		{`{1a:1}`, []FFTok{FFTok_left_bracket, FFTok_error}, FFErr_invalid_char},
+		{`[1] /* x`, []FFTok{FFTok_left_brace, FFTok_integer, FFTok_right_brace, FFTok_error}, FFErr_incomplete_comment},
+		{`[1 /**`, []FFTok{FFTok_left_brace, FFTok_integer, FFTok_error}, FFErr_incomplete_comment},
+	}
+	for _, test := range tests {
+		ffl = NewFFLexer([]byte(test.input))
+		ffl.Relaxed = true
+		assertTokensEqual(t, test.expected, scanAll(ffl))
+		if ffl.Error != test.err || ffl.BigError != nil {
+			t.Fatalf("%s: expected %v, got: %v %v", test.input, test.err, ffl.Error, ffl.BigError)
+		}
+	}
+}
+
+func TestLexerSurrogates(t *testing.T) {
+	tests := []string{
+		`"\ud83d\ude00"`, `"\ud800"`, `"a\ud800b"`, `"\ud800\u0041"`, `"\udc00\ud800"`,
+		`"\ud800\ud800\udc00"`, `"\ud800\n"`, `"\ud800\\u0041"`,
+	}
+
+	for _, test := range tests {
+		var expected string
+		if err := json.Unmarshal([]byte(test), &expected); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", test, err)
+		}
+		ffl := NewFFLexer([]byte(test))
+		if tok := ffl.Scan(); tok != FFTok_string {
+			t.Fatalf("Scan(%s): %v %v", test, tok, ffl.BigError)
+		}
+		if ffl.Output.String() != expected {
+			t.Fatalf("Expected: %q\nGot: %q", expected, ffl.Output.String())
+		}
+	}
+}
+
+func TestWrapErrOnce(t *testing.T) {
+	ffl := NewFFLexer([]byte(`{"a":1}`))
+	ffl.Scan()
+	inner := ffl.WrapErr(errors.New("x"))
+	ffl.Scan()
+	if err := ffl.WrapErr(inner); err != inner {
+		t.Fatalf("Expected the *LexerError as it is, got: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// Limits bounds what is decoded from untrusted input. Zero fields are not
+// limited.
+type Limits struct {
+	MaxSize     int // bytes of input
+	MaxDepth    int // nesting of arrays and objects
+	MaxString   int // bytes of a decoded string
+	MaxElements int // elements of an array, or members of a map
+}
+
+// SizeLimitError is returned for input larger than Limits.MaxSize.
+type SizeLimitError struct {
+	Limit int
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("ffjson: input larger than %d bytes", e.Limit)
+}
+
+// DepthLimitError is returned for arrays and objects nested deeper than
+// Limits.MaxDepth.
+type DepthLimitError struct {
+	Limit int
+}
+
+func (e *DepthLimitError) Error() string {
+	return fmt.Sprintf("ffjson: nesting deeper than %d", e.Limit)
+}
+
+// StringLimitError is returned for a string longer than Limits.MaxString.
+type StringLimitError struct {
+	Limit int
+}
+
+func (e *StringLimitError) Error() string {
+	return fmt.Sprintf("ffjson: string longer than %d bytes", e.Limit)
+}
+
+// ElementLimitError is returned for an array or object with more than
+// Limits.MaxElements elements.
+type ElementLimitError struct {
+	Limit int
+}
+
+func (e *ElementLimitError) Error() string {
+	return fmt.Sprintf("ffjson: more than %d elements in an array or object", e.Limit)
+}
+
+// Check scans data for anything over the limits, for decoders that do not
+// enforce them, like encoding/json.
+func (l Limits) Check(data []byte) error {
+	fs := NewFFLexer(data)
+	fs.Limits = l
+
+	tok := fs.Scan()
+	if tok == FFTok_error {
+		return interfaceTokenError(fs, tok)
+	}
+	if err := fs.SkipField(tok); err != nil {
+		return fs.WrapErr(err)
+	}
+	return nil
+}
+
+// LimitElements returns an *ElementLimitError if n, the elements read so
+// far in an array or object, is over Limits.MaxElements.
+func (ffl *FFLexer) LimitElements(n int) error {
+	if ffl.Limits.MaxElements > 0 && n > ffl.Limits.MaxElements {
+		return &ElementLimitError{Limit: ffl.Limits.MaxElements}
+	}
+	return nil
+}
+
+// enter counts a container opened by Scan against Limits.MaxDepth.
+func (ffl *FFLexer) enter() bool {
+	ffl.depth++
+	if ffl.Limits.MaxDepth > 0 && ffl.depth > ffl.Limits.MaxDepth {
+		ffl.BigError = &DepthLimitError{Limit: ffl.Limits.MaxDepth}
+		return false
+	}
+	return true
+}
+
+// limitString checks a decoded string of n bytes against Limits.MaxString.
+func (ffl *FFLexer) limitString(n int) bool {
+	if ffl.Limits.MaxString > 0 && n > ffl.Limits.MaxString {
+		ffl.BigError = &StringLimitError{Limit: ffl.Limits.MaxString}
+		return false
+	}
+	return true
+}
+
+// IsLimitError reports if err, or the error a *LexerError holds, is over
+// one of the Limits.
+func IsLimitError(err error) bool {
+	if le, ok := err.(*LexerError); ok {
+		err = le.err
+	}
+	switch err.(type) {
+	case *SizeLimitError, *DepthLimitError, *StringLimitError, *ElementLimitError:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLimitsCheck(t *testing.T) {
+	limits := Limits{MaxSize: 64, MaxDepth: 3, MaxString: 5, MaxElements: 3}
+
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{`{"a":[[1,2,3]],"b":"12345"}`, nil},
+		{`{"a":"` + strings.Repeat("x", 64) + `"}`, &SizeLimitError{Limit: 64}},
+		{`[[[[1]]]]`, &DepthLimitError{Limit: 3}},
+		{`{"a":[[[]]]}`, &DepthLimitError{Limit: 3}},
+		{`{"a":"123456"}`, &StringLimitError{Limit: 5}},
+		{`{"abcdef":1}`, &StringLimitError{Limit: 5}},
+		{`[1,2,3,4]`, &ElementLimitError{Limit: 3}},
+		{`{"a":1,"b":[1,2,3],"c":{},"d":2}`, &ElementLimitError{Limit: 3}},
+	}
+
+	fs := NewFFLexer(nil)
+	if IsLimitError(fs.WrapErr(ErrPathNotFound)) || PassError(fs.WrapErr(ErrPathNotFound)) {
+		t.Fatalf("Expected IsLimitError and PassError to be false for ErrPathNotFound")
+	}
+	if IsLimitError(&DuplicateKeyError{Key: "a"}) || !PassError(fs.WrapErr(&DuplicateKeyError{Key: "a"})) {
+		t.Fatalf("Expected only PassError to be true for a DuplicateKeyError")
+	}
+
+	for _, test := range tests {
+		err := limits.Check([]byte(test.input))
+		if test.err == nil {
+			if err != nil {
+				t.Fatalf("Check(%s): %v", test.input, err)
+			}
+			continue
+		}
+		le, ok := err.(*LexerError)
+		if !ok || !reflect.DeepEqual(le.Unwrap(), test.err) || !IsLimitError(err) {
+			t.Fatalf("Check(%s): expected %v, got: %v", test.input, test.err, err)
+		}
+	}
+}
+
+func TestLimitsDecodeInterface(t *testing.T) {
+	fs := NewFFLexer([]byte(`{"a":[1,2],"b":{"c":[]}}`))
+	fs.Limits = Limits{MaxDepth: 3}
+	if _, err := DecodeInterface(fs, fs.Scan()); err != nil {
+		t.Fatalf("DecodeInterface: %v", err)
+	}
+
+	fs.Reset([]byte(`{"a":[1,2],"b":{"c":[[]]}}`))
+	if _, err := DecodeInterface(fs, fs.Scan()); err == nil {
+		t.Fatalf("Expected a DepthLimitError")
+	}
+
+	fs = NewFFLexer([]byte(`{"a":1,"a":2,"a":3}`))
+	fs.Limits = Limits{MaxElements: 2}
+	_, err := DecodeInterface(fs, fs.Scan())
+	if le, ok := err.(*LexerError); !ok || !reflect.DeepEqual(le.Unwrap(), &ElementLimitError{Limit: 2}) {
+		t.Fatalf("Expected an ElementLimitError, got: %v", err)
+	}
+}
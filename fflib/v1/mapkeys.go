@@ -0,0 +1,114 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// KeySlice collects the keys of a map, so generated code can write maps in
+// sorted key order like encoding/json. Names holds the JSON names, and Perm
+// the position in which each name was added, sorted along with Names, so
+// keys kept by the caller in that order can be found again. Integer keys
+// are added to Ints or Uints instead, and sorted by their decimal names
+// without building them.
+type KeySlice struct {
+	Names []string
+	Perm  []int
+	Ints  []int64
+	Uints []uint64
+}
+
+var keySlicePool = sync.Pool{
+	New: func() interface{} { return new(KeySlice) },
+}
+
+// GetKeySlice returns an empty KeySlice from a pool.
+func GetKeySlice() *KeySlice {
+	return keySlicePool.Get().(*KeySlice)
+}
+
+// PutKeySlice returns ks to the pool. It must not be used afterwards.
+func PutKeySlice(ks *KeySlice) {
+	for i := range ks.Names {
+		ks.Names[i] = ""
+	}
+	ks.Names = ks.Names[:0]
+	ks.Perm = ks.Perm[:0]
+	ks.Ints = ks.Ints[:0]
+	ks.Uints = ks.Uints[:0]
+	keySlicePool.Put(ks)
+}
+
+// Add appends the name of the next key.
+func (ks *KeySlice) Add(name string) {
+	ks.Perm = append(ks.Perm, len(ks.Names))
+	ks.Names = append(ks.Names, name)
+}
+
+// AddInt appends a signed integer key.
+func (ks *KeySlice) AddInt(key int64) {
+	ks.Ints = append(ks.Ints, key)
+}
+
+// AddUint appends an unsigned integer key.
+func (ks *KeySlice) AddUint(key uint64) {
+	ks.Uints = append(ks.Uints, key)
+}
+
+// Sort sorts the names, and Perm with them, or else the integer keys.
+func (ks *KeySlice) Sort() {
+	switch {
+	case len(ks.Ints) != 0:
+		sort.Sort(intKeys(ks.Ints))
+	case len(ks.Uints) != 0:
+		sort.Sort(uintKeys(ks.Uints))
+	default:
+		sort.Sort(ks)
+	}
+}
+
+func (ks *KeySlice) Len() int           { return len(ks.Names) }
+func (ks *KeySlice) Less(i, j int) bool { return ks.Names[i] < ks.Names[j] }
+func (ks *KeySlice) Swap(i, j int) {
+	ks.Names[i], ks.Names[j] = ks.Names[j], ks.Names[i]
+	ks.Perm[i], ks.Perm[j] = ks.Perm[j], ks.Perm[i]
+}
+
+// intKeys sorts integers by their decimal names.
+type intKeys []int64
+
+func (k intKeys) Len() int      { return len(k) }
+func (k intKeys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k intKeys) Less(i, j int) bool {
+	var a, b [20]byte
+	return bytes.Compare(strconv.AppendInt(a[:0], k[i], 10), strconv.AppendInt(b[:0], k[j], 10)) < 0
+}
+
+// uintKeys sorts unsigned integers by their decimal names.
+type uintKeys []uint64
+
+func (k uintKeys) Len() int      { return len(k) }
+func (k uintKeys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k uintKeys) Less(i, j int) bool {
+	var a, b [20]byte
+	return bytes.Compare(strconv.AppendUint(a[:0], k[i], 10), strconv.AppendUint(b[:0], k[j], 10)) < 0
+}
@@ -0,0 +1,76 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestKeySlice(t *testing.T) {
+	ks := GetKeySlice()
+	keys := []int{10, 2, 33, 1}
+	for _, k := range keys {
+		ks.Add(strconv.Itoa(k))
+	}
+	ks.Sort()
+
+	expected := []string{"1", "10", "2", "33"}
+	for i, name := range ks.Names {
+		if name != expected[i] {
+			t.Fatalf("Expected %v, got: %v", expected, ks.Names)
+		}
+		if strconv.Itoa(keys[ks.Perm[i]]) != name {
+			t.Fatalf("Perm %v does not follow %v", ks.Perm, ks.Names)
+		}
+	}
+
+	PutKeySlice(ks)
+	ks = GetKeySlice()
+	if len(ks.Names) != 0 || len(ks.Perm) != 0 {
+		t.Fatalf("Expected an empty KeySlice from the pool, got: %v", ks)
+	}
+}
+
+func TestKeySliceInts(t *testing.T) {
+	ks := GetKeySlice()
+	for _, k := range []int64{10, -2, 2, -10, 1, -9223372036854775808} {
+		ks.AddInt(k)
+	}
+	ks.Sort()
+	expected := []int64{-10, -2, -9223372036854775808, 1, 10, 2}
+	for i, k := range ks.Ints {
+		if k != expected[i] {
+			t.Fatalf("Expected %v, got: %v", expected, ks.Ints)
+		}
+	}
+	PutKeySlice(ks)
+
+	ks = GetKeySlice()
+	for _, k := range []uint64{10, 2, 18446744073709551615, 1} {
+		ks.AddUint(k)
+	}
+	ks.Sort()
+	expectedUints := []uint64{1, 10, 18446744073709551615, 2}
+	for i, k := range ks.Uints {
+		if k != expectedUints[i] {
+			t.Fatalf("Expected %v, got: %v", expectedUints, ks.Uints)
+		}
+	}
+	PutKeySlice(ks)
+}
@@ -0,0 +1,101 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"strings"
+)
+
+// Helpers for RFC 6901 JSON Pointers, used by the generated
+// GetPointer and SetPointer methods.
+
+// ErrPointerSyntax is returned for pointers that are not valid RFC 6901 syntax.
+var ErrPointerSyntax = errors.New("ffjson: invalid json pointer")
+
+// ErrPointerNotFound is returned when a pointer does not resolve to a value.
+var ErrPointerNotFound = errors.New("ffjson: json pointer not found")
+
+// SplitPointer splits the first reference token off a non-empty JSON Pointer.
+// The token is returned unescaped ("~1" is "/", "~0" is "~"), rest is the
+// remaining pointer and is "" when tok was the last token.
+func SplitPointer(ptr string) (tok string, rest string, err error) {
+	if len(ptr) == 0 || ptr[0] != '/' {
+		return "", "", ErrPointerSyntax
+	}
+
+	tok = ptr[1:]
+	if i := strings.IndexByte(tok, '/'); i >= 0 {
+		tok, rest = tok[:i], tok[i:]
+	}
+
+	if strings.IndexByte(tok, '~') >= 0 {
+		tok, err = unescapePointerToken(tok)
+	}
+	return tok, rest, err
+}
+
+func unescapePointerToken(tok string) (string, error) {
+	out := make([]byte, 0, len(tok))
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c != '~' {
+			out = append(out, c)
+			continue
+		}
+		if i+1 >= len(tok) {
+			return "", ErrPointerSyntax
+		}
+		i++
+		switch tok[i] {
+		case '0':
+			out = append(out, '~')
+		case '1':
+			out = append(out, '/')
+		default:
+			return "", ErrPointerSyntax
+		}
+	}
+	return string(out), nil
+}
+
+// PointerIndex parses an array index reference token for an array of length n.
+// The "-" token refers to the (nonexistent) element after the last one and
+// yields n; any other index must be a canonical decimal number below n.
+func PointerIndex(tok string, n int) (int, error) {
+	if tok == "-" {
+		return n, nil
+	}
+
+	if len(tok) == 0 || (len(tok) > 1 && tok[0] == '0') {
+		return 0, ErrPointerNotFound
+	}
+
+	idx := 0
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		if c < '0' || c > '9' {
+			return 0, ErrPointerNotFound
+		}
+		idx = idx*10 + int(c-'0')
+		if idx >= n {
+			return 0, ErrPointerNotFound
+		}
+	}
+	return idx, nil
+}
@@ -0,0 +1,81 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestSplitPointer(t *testing.T) {
+	var testvecs = []struct {
+		ptr  string
+		tok  string
+		rest string
+	}{
+		{"/a", "a", ""},
+		{"/address/city", "address", "/city"},
+		{"/", "", ""},
+		{"//x", "", "/x"},
+		{"/a~1b/c", "a/b", "/c"},
+		{"/m~0n", "m~n", ""},
+		{"/~01", "~1", ""},
+	}
+
+	for _, v := range testvecs {
+		tok, rest, err := SplitPointer(v.ptr)
+		if err != nil {
+			t.Fatalf("unexpected SplitPointer error: %v from %v", err, v.ptr)
+		}
+		if tok != v.tok || rest != v.rest {
+			t.Fatalf("SplitPointer(%q): expected %q %q, got %q %q", v.ptr, v.tok, v.rest, tok, rest)
+		}
+	}
+
+	for _, ptr := range []string{"", "a", "/~", "/~2"} {
+		_, _, err := SplitPointer(ptr)
+		if err != ErrPointerSyntax {
+			t.Fatalf("SplitPointer(%q): expected ErrPointerSyntax, got %v", ptr, err)
+		}
+	}
+}
+
+func TestPointerIndex(t *testing.T) {
+	var testvecs = map[string]int{
+		"0":  0,
+		"3":  3,
+		"10": 10,
+		"-":  11,
+	}
+
+	for tok, expected := range testvecs {
+		idx, err := PointerIndex(tok, 11)
+		if err != nil {
+			t.Fatalf("unexpected PointerIndex error: %v from %v", err, tok)
+		}
+		if idx != expected {
+			t.Fatalf("PointerIndex(%q): expected %v, got %v", tok, expected, idx)
+		}
+	}
+
+	for _, tok := range []string{"", "01", "11", "1a", "-1", "+1"} {
+		_, err := PointerIndex(tok, 11)
+		if err != ErrPointerNotFound {
+			t.Fatalf("PointerIndex(%q): expected ErrPointerNotFound, got %v", tok, err)
+		}
+	}
+}
@@ -18,18 +18,21 @@
 package v1
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"unicode"
 	"unicode/utf16"
+	"unicode/utf8"
 )
 
 const sliceStringMask = cIJC | cNFP
 
 type ffReader struct {
-	s []byte
-	i int
-	l int
+	s    []byte
+	i    int
+	l    int
+	utf8 UTF8
 }
 
 func newffReader(d []byte) *ffReader {
@@ -60,19 +63,43 @@ func (r *ffReader) Reset(d []byte) {
 // it will iterate the buffer from the beginning, and should
 // only be used in error-paths.
 func (r *ffReader) PosWithLine() (int, int) {
-	currentLine := 1
-	currentChar := 0
-
-	for i := 0; i < r.i; i++ {
-		c := r.s[i]
-		currentChar++
-		if c == '\n' {
-			currentLine++
-			currentChar = 0
+	s := r.s[:r.i]
+	return bytes.Count(s, newline) + 1, len(s) - (bytes.LastIndexByte(s, '\n') + 1)
+}
+
+var newline = []byte{'\n'}
+
+// lineAround returns a copy of the line holding the position, without its
+// line break and with at most max bytes on each side of the position, the
+// position in the copy, and if the line went on past the copy.
+func (r *ffReader) lineAround(max int) ([]byte, int, bool) {
+	start := bytes.LastIndexByte(r.s[:r.i], '\n') + 1
+	end := bytes.IndexByte(r.s[r.i:r.l], '\n')
+	if end < 0 {
+		end = r.l
+	} else {
+		end += r.i
+	}
+	if end > r.i && r.s[end-1] == '\r' {
+		end--
+	}
+	more := false
+	if end-r.i > max {
+		end = r.i + max
+		for end > r.i && !utf8.RuneStart(r.s[end]) {
+			end--
 		}
+		more = true
 	}
-
-	return currentLine, currentChar
+	if r.i-start > max {
+		start = r.i - max
+		for start < r.i && !utf8.RuneStart(r.s[start]) {
+			start++
+		}
+	}
+	line := make([]byte, end-start)
+	copy(line, r.s[start:end])
+	return line, r.i - start, more
 }
 
 func (r *ffReader) ReadByteNoWS() (byte, error) {
@@ -111,6 +138,23 @@ func (r *ffReader) ReadByteNoWS() (byte, error) {
 	}
 }
 
+// PeekNoWS returns the next byte that is not whitespace, without reading it.
+func (r *ffReader) PeekNoWS() (byte, bool) {
+	for j := r.i; j < r.l; j++ {
+		if whitespaceLookupTable[r.s[j]] == false {
+			return r.s[j], true
+		}
+	}
+	return 0, false
+}
+
+// SkipBOM skips a UTF-8 byte order mark at the start of the input.
+func (r *ffReader) SkipBOM() {
+	if r.i == 0 && r.l >= 3 && r.s[0] == 0xef && r.s[1] == 0xbb && r.s[2] == 0xbf {
+		r.i = 3
+	}
+}
+
 func (r *ffReader) ReadByte() (byte, error) {
 	if r.i >= r.l {
 		return 0, io.EOF
@@ -154,7 +198,9 @@ func (r *ffReader) readU4(j int) (rune, error) {
 	return rune(rr), nil
 }
 
-func (r *ffReader) handleEscaped(c byte, j int, out DecodingBuffer) (int, error) {
+// handleEscaped writes the escape sequence whose backslash is at j-1, in
+// the string starting at start.
+func (r *ffReader) handleEscaped(c byte, j int, start int, out DecodingBuffer) (int, error) {
 	if j >= r.l {
 		return 0, io.EOF
 	}
@@ -169,18 +215,32 @@ func (r *ffReader) handleEscaped(c byte, j int, out DecodingBuffer) (int, error)
 		}
 
 		if utf16.IsSurrogate(ru) {
-			ru2, err := r.readU4(j + 6)
-			if err != nil {
-				return 0, err
-			}
 			out.Write(r.s[r.i : j-2])
-			r.i = j + 10
+			r.i = j + 4
 			j = r.i
-			rval := utf16.DecodeRune(ru, ru2)
-			if rval != unicode.ReplacementChar {
-				out.WriteRune(rval)
-			} else {
-				return 0, fmt.Errorf("lex_string_invalid_unicode_surrogate: %v %v", ru, ru2)
+			// As in encoding/json, a surrogate pair is a high surrogate
+			// followed by the \u escape of a low one. Any other surrogate
+			// is invalid UTF-8 once decoded, and follows the UTF8 policy;
+			// what follows it is read as usual.
+			if j+1 < r.l && r.s[j] == '\\' && r.s[j+1] == 'u' {
+				if ru2, err := r.readU4(j + 2); err == nil {
+					if rval := utf16.DecodeRune(ru, ru2); rval != unicode.ReplacementChar {
+						r.i = j + 6
+						out.WriteRune(rval)
+						return r.i, nil
+					}
+				}
+			}
+			switch r.utf8 {
+			case UTF8Reject:
+				// leave the position at the backslash for LexerError
+				r.i = j - 6
+				return 0, &InvalidUTF8Error{Offset: j - 6 - start}
+			case UTF8Pass:
+				// the 3 bytes UTF-8 would give the surrogate
+				out.Write([]byte{0xe0 | byte(ru>>12), 0x80 | byte(ru>>6)&0x3f, 0x80 | byte(ru)&0x3f})
+			default:
+				out.WriteRune(unicode.ReplacementChar)
 			}
 		} else {
 			out.Write(r.s[r.i : j-2])
@@ -219,17 +279,40 @@ func (r *ffReader) handleEscaped(c byte, j int, out DecodingBuffer) (int, error)
 	return j, nil
 }
 
+// handleNonASCII checks the UTF-8 sequence whose first byte is at j-1, in
+// the string starting at start, following the UTF8 policy.
+func (r *ffReader) handleNonASCII(j int, start int, out DecodingBuffer) (int, error) {
+	if _, size := utf8.DecodeRune(r.s[j-1:]); size > 1 {
+		return j + size - 1, nil
+	}
+	if r.utf8 == UTF8Reject {
+		// leave the position at the invalid byte for LexerError
+		r.i = j - 1
+		return 0, &InvalidUTF8Error{Offset: j - 1 - start}
+	}
+	out.Write(r.s[r.i : j-1])
+	out.WriteString("\ufffd")
+	r.i = j
+	return j, nil
+}
+
 func (r *ffReader) SliceString(out DecodingBuffer) error {
 	var c byte
 	// TODO(pquerna): string_with_escapes? de-escape here?
 	j := r.i
+	start := r.i
+
+	mask := sliceStringMask
+	if r.utf8 != UTF8Pass {
+		mask |= cNUC
+	}
 
 	for {
 		if j >= r.l {
 			return io.EOF
 		}
 
-		j, c = scanString(r.s, j)
+		j, c = scanString(r.s, j, mask)
 
 		if c == '"' {
 			if j != r.i {
@@ -239,12 +322,18 @@ func (r *ffReader) SliceString(out DecodingBuffer) error {
 			return nil
 		} else if c == '\\' {
 			var err error
-			j, err = r.handleEscaped(c, j, out)
+			j, err = r.handleEscaped(c, j, start, out)
 			if err != nil {
 				return err
 			}
 		} else if byteLookupTable[c]&cIJC != 0 {
 			return fmt.Errorf("lex_string_invalid_json_char: %v", c)
+		} else if c >= utf8.RuneSelf {
+			var err error
+			j, err = r.handleNonASCII(j, start, out)
+			if err != nil {
+				return err
+			}
 		}
 		continue
 	}
@@ -252,6 +341,49 @@ func (r *ffReader) SliceString(out DecodingBuffer) error {
 	panic("ffjson: SliceString unreached exit")
 }
 
+// SliceSingleQuoted is SliceString for the single-quoted strings of the
+// relaxed mode, where " needs no escape and \' is allowed.
+func (r *ffReader) SliceSingleQuoted(out DecodingBuffer) error {
+	j := r.i
+	start := r.i
+
+	for {
+		if j >= r.l {
+			return io.EOF
+		}
+
+		c := r.s[j]
+		j++
+
+		if c == '\'' {
+			out.Write(r.s[r.i : j-1])
+			r.i = j
+			return nil
+		} else if c == '\\' {
+			if j < r.l && r.s[j] == '\'' {
+				out.Write(r.s[r.i : j-1])
+				out.WriteByte('\'')
+				j++
+				r.i = j
+				continue
+			}
+			var err error
+			j, err = r.handleEscaped(c, j, start, out)
+			if err != nil {
+				return err
+			}
+		} else if c < 0x20 {
+			return fmt.Errorf("lex_string_invalid_json_char: %v", c)
+		} else if c >= utf8.RuneSelf && r.utf8 != UTF8Pass {
+			var err error
+			j, err = r.handleNonASCII(j, start, out)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // TODO(pquerna): consider combining wibth the normal byte mask.
 var whitespaceLookupTable [256]bool = [256]bool{
 	false, /* 0 */
@@ -17,7 +17,7 @@
 
 package v1
 
-func scanString(s []byte, j int) (int, byte) {
+func scanString(s []byte, j int, mask int8) (int, byte) {
 	for {
 		if j >= len(s) {
 			return j, 0
@@ -25,7 +25,7 @@ func scanString(s []byte, j int) (int, byte) {
 
 		c := s[j]
 		j++
-		if byteLookupTable[c]&sliceStringMask == 0 {
+		if byteLookupTable[c]&mask == 0 {
 			continue
 		}
 
@@ -0,0 +1,338 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RedactedValue replaces the value of fields tagged with redact, and of
+// masked strings too short to keep any characters.
+const RedactedValue = "***"
+
+// redacter is implemented by buffers that can ask for redacted output.
+type redacter interface {
+	Redact() bool
+}
+
+// Redacting reports if fields tagged with redact or mask are to be hidden
+// in what is written to buf, see Buffer.SetRedact.
+func Redacting(buf EncodingBuffer) bool {
+	if r, ok := buf.(redacter); ok {
+		return r.Redact()
+	}
+	return false
+}
+
+// WriteMasked writes s as a JSON string, keeping its first head and last
+// tail characters and replacing every other one with '*'. A string of at
+// most head+tail characters is written as RedactedValue.
+func WriteMasked(buf EncodingBuffer, s string, head, tail int) {
+	n := utf8.RuneCountInString(s)
+	if n <= head+tail {
+		WriteJsonString(buf, RedactedValue)
+		return
+	}
+
+	b := make([]byte, 0, len(s))
+	i := 0
+	for _, c := range s {
+		if i < head || i >= n-tail {
+			b = append(b, string(c)...)
+		} else {
+			b = append(b, '*')
+		}
+		i++
+	}
+	WriteJson(buf, b)
+}
+
+// RedactError is returned when redacting a value that encoding/json would
+// write with fields tagged with redact or mask in clear: a struct without
+// generated code holding such fields, directly or in a nested value, or a
+// value that may hold them written by its own MarshalJSON or MarshalText.
+type RedactError struct {
+	Type reflect.Type
+	// Method is the MarshalJSON or MarshalText method writing the value,
+	// or empty for a struct without generated code.
+	Method string
+}
+
+func (e *RedactError) Error() string {
+	if e.Method != "" {
+		return "ffjson: cannot redact " + e.Type.String() + ", it is written by its " + e.Method + " method"
+	}
+	return "ffjson: cannot redact " + e.Type.String() + ", it has no generated encoder"
+}
+
+var (
+	bufMarshalerType  = reflect.TypeOf((*variantMarshaler)(nil)).Elem()
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// maxRedactDepth bounds the nesting walked by valueRedacts.
+const maxRedactDepth = 1000
+
+var redactTypes = struct {
+	sync.RWMutex
+	m map[reflect.Type]bool
+}{m: make(map[reflect.Type]bool)}
+
+// mayRedact reports if values of t may hold fields tagged with redact or
+// mask. Interfaces may hold anything, their values are checked instead.
+func mayRedact(t reflect.Type) bool {
+	redactTypes.RLock()
+	may, ok := redactTypes.m[t]
+	redactTypes.RUnlock()
+	if ok {
+		return may
+	}
+
+	may = typeRedacts(t, make(map[reflect.Type]bool))
+	redactTypes.Lock()
+	redactTypes.m[t] = may
+	redactTypes.Unlock()
+	return may
+}
+
+func typeRedacts(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return typeRedacts(t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			return false
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if tagRedacts(f.Tag.Get("ffjson")) || typeRedacts(f.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tagRedacts reports if an ffjson tag has the redact or mask option.
+func tagRedacts(tag string) bool {
+	for _, opt := range strings.Split(tag, ",")[1:] {
+		if opt == "redact" || strings.HasPrefix(opt, "mask") {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeRedacted writes v, which may hold fields to redact, like
+// encoding/json. Maps, slices, arrays, pointers and interfaces are walked,
+// so generated encoders write the values that have one, with the policies
+// of buf. Other structs reaching fields to redact, and values encoding/json
+// would write with their own methods, are a *RedactError.
+func encodeRedacted(buf *Buffer, v reflect.Value) error {
+	t := v.Type()
+	if t.Kind() != reflect.Interface && !mayRedact(t) {
+		return buf.encodeJSON(v.Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if t.Kind() == reflect.Ptr && t.Implements(bufMarshalerType) {
+			return v.Interface().(variantMarshaler).MarshalJSONBuf(buf)
+		}
+		if m := marshalMethod(v); t.Kind() == reflect.Ptr && m != "" {
+			return &RedactError{Type: t, Method: m}
+		}
+		return encodeRedacted(buf, v.Elem())
+
+	case reflect.Struct:
+		if reflect.PtrTo(t).Implements(bufMarshalerType) {
+			p := reflect.New(t)
+			p.Elem().Set(v)
+			return p.Interface().(variantMarshaler).MarshalJSONBuf(buf)
+		}
+		if m := marshalMethod(v); m != "" {
+			return &RedactError{Type: t, Method: m}
+		}
+		if valueRedacts(v, 0) {
+			return &RedactError{Type: t}
+		}
+		return buf.encodeJSON(v.Interface())
+
+	case reflect.Map:
+		if m := marshalMethod(v); m != "" {
+			return &RedactError{Type: t, Method: m}
+		}
+		return encodeRedactedMap(buf, v)
+
+	case reflect.Slice, reflect.Array:
+		if m := marshalMethod(v); m != "" {
+			return &RedactError{Type: t, Method: m}
+		}
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeRedacted(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+	return buf.encodeJSON(v.Interface())
+}
+
+// marshalMethod returns "MarshalJSON" or "MarshalText" if encoding/json
+// writes v with that method, where the fields to redact cannot be reached,
+// or "" otherwise.
+func marshalMethod(v reflect.Value) string {
+	types := []reflect.Type{v.Type()}
+	if v.CanAddr() {
+		types = append(types, reflect.PtrTo(v.Type()))
+	}
+	for _, t := range types {
+		if t.Implements(marshalerType) {
+			return "MarshalJSON"
+		}
+	}
+	for _, t := range types {
+		if t.Implements(textMarshalerType) {
+			return "MarshalText"
+		}
+	}
+	return ""
+}
+
+func encodeRedactedMap(buf *Buffer, v reflect.Value) error {
+	if v.IsNil() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	keys := v.MapKeys()
+	ks := GetKeySlice()
+	defer PutKeySlice(ks)
+	for _, k := range keys {
+		name, err := mapKeyName(k)
+		if err != nil {
+			return err
+		}
+		ks.Add(name)
+	}
+	ks.Sort()
+
+	buf.WriteByte('{')
+	for i, name := range ks.Names {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		WriteJsonString(buf, name)
+		buf.WriteByte(':')
+		if err := encodeRedacted(buf, v.MapIndex(keys[ks.Perm[i]])); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// mapKeyName returns the JSON name of a map key, as encoding/json does.
+func mapKeyName(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		b, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", &json.UnsupportedTypeError{Type: k.Type()}
+}
+
+// valueRedacts reports if v, written by encoding/json, would reach a field
+// tagged with redact or mask. Values nested too deep, as in cycles, are
+// assumed to.
+func valueRedacts(v reflect.Value, depth int) bool {
+	if depth > maxRedactDepth {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		return !v.IsNil() && valueRedacts(v.Elem(), depth+1)
+	}
+	if !mayRedact(v.Type()) {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if tagRedacts(f.Tag.Get("ffjson")) || valueRedacts(v.Field(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if valueRedacts(v.Index(i), depth+1) {
+				return true
+			}
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			if valueRedacts(v.MapIndex(k), depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type redactToken struct {
+	Token string `json:"token" ffjson:",redact"`
+}
+
+// redactGenerated stands for a type with a generated encoder.
+type redactGenerated struct {
+	Token string `json:"token" ffjson:",redact"`
+}
+
+func (r *redactGenerated) MarshalJSONBuf(buf EncodingBuffer) error {
+	buf.WriteString(`{"token":`)
+	if Redacting(buf) {
+		WriteJsonString(buf, RedactedValue)
+	} else {
+		WriteJsonString(buf, r.Token)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// redactList writes itself, leaving the tokens of its elements in clear.
+type redactList []redactGenerated
+
+func (l redactList) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]redactGenerated(l))
+}
+
+// redactNames writes itself as text.
+type redactNames map[string]*redactGenerated
+
+func (n redactNames) MarshalText() ([]byte, error) {
+	return []byte("names"), nil
+}
+
+// redactPtrList writes itself through a pointer.
+type redactPtrList []*redactGenerated
+
+func (l *redactPtrList) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]*redactGenerated(*l))
+}
+
+func TestWriteMasked(t *testing.T) {
+	tests := []struct {
+		in         string
+		head, tail int
+		expected   string
+	}{
+		{"13812345678", 3, 4, `"138****5678"`},
+		{"张三丰", 1, 0, `"张**"`},
+		{"abc", 2, 1, `"***"`},
+		{"token", 0, 0, `"*****"`},
+	}
+
+	for _, test := range tests {
+		var buf Buffer
+		WriteMasked(&buf, test.in, test.head, test.tail)
+		if buf.String() != test.expected {
+			t.Fatalf("Expected: %v\nGot: %v", test.expected, buf.String())
+		}
+	}
+
+	var buf Buffer
+	if Redacting(&buf) {
+		t.Fatalf("Expected no redaction by default")
+	}
+	buf.SetRedact(true)
+	buf.Reset()
+	if !Redacting(&buf) {
+		t.Fatalf("Expected redaction to be kept across Reset")
+	}
+}
+
+func TestEncodeRedacted(t *testing.T) {
+	tests := []struct {
+		v        interface{}
+		expected string
+	}{
+		{map[string]redactGenerated{"b": {"x"}, "a": {"y"}}, `{"a":{"token":"***"},"b":{"token":"***"}}`},
+		{[]interface{}{&redactGenerated{"x"}, nil, 1}, `[{"token":"***"},null,1]`},
+		{map[int][]*redactGenerated{2: nil, 1: {nil}}, `{"1":[null],"2":null}`},
+		{map[string]interface{}{"a": []string{"x"}}, `{"a":["x"]}`},
+		{struct{ A []int }{[]int{1}}, `{"A":[1]}`},
+		{struct{ A interface{} }{1}, `{"A":1}`},
+	}
+
+	for _, test := range tests {
+		var buf Buffer
+		buf.SetRedact(true)
+		if err := buf.Encode(test.v); err != nil {
+			t.Fatalf("Encode(%v): %v", test.v, err)
+		}
+		if buf.String() != test.expected {
+			t.Fatalf("Expected: %v\nGot: %v", test.expected, buf.String())
+		}
+	}
+
+	errTests := []struct {
+		v       interface{}
+		method  string
+		message string
+	}{
+		{redactToken{"x"}, "", "ffjson: cannot redact v1.redactToken, it has no generated encoder"},
+		{map[string]interface{}{"a": []interface{}{&redactToken{"x"}}}, "", ""},
+		{struct{ A interface{} }{redactGenerated{"x"}}, "", ""},
+		{redactList{{"x"}}, "MarshalJSON", "ffjson: cannot redact v1.redactList, it is written by its MarshalJSON method"},
+		{map[string]interface{}{"a": redactList{{"x"}}}, "MarshalJSON", ""},
+		{[]redactNames{{"a": {"x"}}}, "MarshalText", "ffjson: cannot redact v1.redactNames, it is written by its MarshalText method"},
+		{&redactPtrList{{"x"}}, "MarshalJSON", ""},
+		{struct{ A redactPtrList }{redactPtrList{{"x"}}}, "", ""},
+	}
+	for _, test := range errTests {
+		var buf Buffer
+		buf.SetRedact(true)
+		err := buf.Encode(test.v)
+		re, ok := err.(*RedactError)
+		if !ok || re.Method != test.method {
+			t.Fatalf("Expected *RedactError with method %q for %#v, got: %v", test.method, test.v, err)
+		}
+		if test.message != "" && err.Error() != test.message {
+			t.Fatalf("Expected: %s\nGot: %v", test.message, err)
+		}
+
+		// Without redaction, encoding/json writes them.
+		buf.SetRedact(false)
+		if err := buf.Encode(test.v); err != nil {
+			t.Fatalf("Encode(%v): %v", test.v, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// RelaxedToJSON rewrites data, read in the relaxed mode of FFLexer, as
+// standard JSON for decoders other than the generated ones. Only the
+// tokens are rewritten, so the structure is checked by the decoder.
+func RelaxedToJSON(data []byte) ([]byte, error) {
+	fs := NewFFLexer(data)
+	fs.Relaxed = true
+
+	tok := fs.Scan()
+	switch tok {
+	case FFTok_error, FFTok_eof:
+		return nil, interfaceTokenError(fs, tok)
+	}
+
+	raw, err := fs.CaptureField(tok)
+	if err != nil {
+		return nil, fs.WrapErr(err)
+	}
+	// The next Scan reuses the Output buffer.
+	raw = append([]byte(nil), raw...)
+
+	if err := fs.ScanEOF(); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
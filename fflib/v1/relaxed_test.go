@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRelaxedToJSON(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{a: 1, 'b': [0x10, +1, .5,],}`, `{"a": 1,"b": [16,1,0.5]}`},
+		{`[1, /* c */ ]`, `[1]`},
+		{`[1] // the end`, `[1]`},
+		{`[]`, `[]`},
+	}
+	for _, test := range tests {
+		raw, err := RelaxedToJSON([]byte(test.input))
+		if err != nil {
+			t.Fatalf("RelaxedToJSON(%s): %v", test.input, err)
+		}
+		if string(raw) != test.expected {
+			t.Fatalf("Expected: %s\nGot: %s", test.expected, raw)
+		}
+		if !json.Valid(raw) {
+			t.Fatalf("RelaxedToJSON(%s) is not JSON: %s", test.input, raw)
+		}
+	}
+
+	errors := []struct {
+		input  string
+		offset int
+	}{
+		{`[,]`, 2},
+		{`{,}`, 2},
+		{`[1,,]`, 4},
+		{`{a:1,,}`, 6},
+		{`{1a:1}`, 2},
+		{`[1+2]`, 2},
+		{`[01]`, 2},
+		{`[1] /* x`, 8},
+		{`[1] /`, 5},
+	}
+	for _, test := range errors {
+		raw, err := RelaxedToJSON([]byte(test.input))
+		le, ok := err.(*LexerError)
+		if !ok {
+			t.Fatalf("RelaxedToJSON(%s): expected *LexerError, got: %s %v", test.input, raw, err)
+		}
+		if le.Offset() != test.offset {
+			t.Fatalf("RelaxedToJSON(%s): expected the error at %d, got: %v", test.input, test.offset, le)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"time"
+)
+
+// TimeLocation is the location generated code parses times in when they
+// carry no zone offset, and formats times with an explicit layout in.
+// Unix timestamps are decoded into it as well.
+var TimeLocation = time.Local
+
+// ErrTimeYear is returned when a time with the default layout has a year
+// outside of [0,9999], which time.Time's MarshalJSON rejects too.
+var ErrTimeYear = errors.New("ffjson: time year outside of range [0,9999]")
+
+// WriteTime writes t as a JSON string. An empty layout writes
+// time.RFC3339Nano in the location of t, like time.Time's MarshalJSON;
+// other layouts format t in TimeLocation. The layout must not produce
+// characters that need escaping.
+func WriteTime(buf EncodingBuffer, t time.Time, layout string) error {
+	if layout == "" {
+		if y := t.Year(); y < 0 || y >= 10000 {
+			return ErrTimeYear
+		}
+		layout = time.RFC3339Nano
+	} else {
+		t = t.In(TimeLocation)
+	}
+
+	buf.WriteByte('"')
+	if b, ok := buf.(*Buffer); ok {
+		// Format in place, avoiding a temporary slice.
+		b.buf = t.AppendFormat(b.buf, layout)
+	} else {
+		var scratch [64]byte
+		buf.Write(t.AppendFormat(scratch[:0], layout))
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+// WriteUnixTime writes t as a JSON number of seconds since the Unix epoch,
+// or milliseconds if milli is set.
+func WriteUnixTime(buf EncodingBuffer, t time.Time, milli bool) {
+	n := t.Unix()
+	if milli {
+		n = n*1000 + int64(t.Nanosecond())/1e6
+	}
+	FormatBits2(buf, uint64(n), 10, n < 0)
+}
+
+// ParseTime parses the contents of a JSON string. An empty layout accepts
+// time.RFC3339, like time.Time's UnmarshalJSON; other layouts are parsed
+// in TimeLocation.
+func ParseTime(b []byte, layout string) (time.Time, error) {
+	if layout == "" {
+		return time.Parse(time.RFC3339, string(b))
+	}
+	return time.ParseInLocation(layout, string(b), TimeLocation)
+}
+
+// ParseUnixTime parses a JSON number of seconds since the Unix epoch, or
+// milliseconds if milli is set. The result is in TimeLocation.
+func ParseUnixTime(b []byte, milli bool) (time.Time, error) {
+	n, err := ParseInt(b, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if milli {
+		return time.Unix(n/1000, (n%1000)*1e6).In(TimeLocation), nil
+	}
+	return time.Unix(n, 0).In(TimeLocation), nil
+}
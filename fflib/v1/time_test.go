@@ -0,0 +1,89 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteTime(t *testing.T) {
+	loc := TimeLocation
+	defer func() { TimeLocation = loc }()
+	TimeLocation = time.FixedZone("CST", 8*3600)
+
+	tm := time.Date(2017, 3, 4, 5, 6, 7, 8000000, time.UTC)
+
+	var buf Buffer
+	if err := WriteTime(&buf, tm, ""); err != nil {
+		t.Fatalf("WriteTime: %v", err)
+	}
+	expected, _ := json.Marshal(tm)
+	if buf.String() != string(expected) {
+		t.Fatalf("Expected: %v\nGot: %v", string(expected), buf.String())
+	}
+
+	buf.Reset()
+	WriteTime(&buf, tm, "2006-01-02 15:04:05")
+	if buf.String() != `"2017-03-04 13:06:07"` {
+		t.Fatalf("Expected layout in TimeLocation, got: %v", buf.String())
+	}
+
+	buf.Reset()
+	WriteUnixTime(&buf, tm, true)
+	if buf.String() != "1488603967008" {
+		t.Fatalf("Expected unix milliseconds, got: %v", buf.String())
+	}
+
+	if err := WriteTime(&buf, time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC), ""); err != ErrTimeYear {
+		t.Fatalf("Expected ErrTimeYear, got: %v", err)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	loc := TimeLocation
+	defer func() { TimeLocation = loc }()
+	TimeLocation = time.FixedZone("CST", 8*3600)
+
+	expected := time.Date(2017, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tm, err := ParseTime([]byte("2017-03-04T05:06:07Z"), "")
+	if err != nil || !tm.Equal(expected) {
+		t.Fatalf("ParseTime: %v %v", tm, err)
+	}
+
+	tm, err = ParseTime([]byte("2017-03-04 13:06:07"), "2006-01-02 15:04:05")
+	if err != nil || !tm.Equal(expected) {
+		t.Fatalf("ParseTime with layout: %v %v", tm, err)
+	}
+
+	tm, err = ParseUnixTime([]byte("1488603967"), false)
+	if err != nil || !tm.Equal(expected) || tm.Location() != TimeLocation {
+		t.Fatalf("ParseUnixTime: %v %v", tm, err)
+	}
+
+	tm, err = ParseUnixTime([]byte("-1500"), true)
+	if err != nil || !tm.Equal(time.Unix(-2, 500000000)) {
+		t.Fatalf("ParseUnixTime milli: %v %v", tm, err)
+	}
+
+	if _, err = ParseTime([]byte("2017-03-04"), ""); err == nil {
+		t.Fatalf("Expected error for a date without time")
+	}
+}
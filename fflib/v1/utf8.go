@@ -0,0 +1,67 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+)
+
+// UTF8 selects how invalid UTF-8 in strings is handled, when decoding
+// (FFLexer.UTF8) and when encoding (Buffer.SetUTF8). The zero value
+// replaces it, like encoding/json.
+type UTF8 uint8
+
+const (
+	// UTF8Replace turns every invalid byte into U+FFFD.
+	UTF8Replace UTF8 = iota
+	// UTF8Reject fails with an *InvalidUTF8Error.
+	UTF8Reject
+	// UTF8Pass keeps invalid bytes as they are.
+	UTF8Pass
+)
+
+// InvalidUTF8Error is the error for a string with invalid UTF-8 under
+// UTF8Reject. When decoding it is held by a *LexerError, positioned at
+// the invalid byte.
+type InvalidUTF8Error struct {
+	Offset int // of the first invalid byte in the string
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("ffjson: invalid UTF-8 in string at byte %d", e.Offset)
+}
+
+// utf8Handler is implemented by buffers holding a UTF8 policy.
+type utf8Handler interface {
+	UTF8() UTF8
+	failUTF8(err error)
+}
+
+// invalidUTF8 returns the policy of buf for an invalid byte at offset i of
+// a string, recording the error under UTF8Reject.
+func invalidUTF8(buf JsonStringWriter, i int) UTF8 {
+	h, ok := buf.(utf8Handler)
+	if !ok {
+		return UTF8Replace
+	}
+	p := h.UTF8()
+	if p == UTF8Reject {
+		h.failUTF8(&InvalidUTF8Error{Offset: i})
+	}
+	return p
+}
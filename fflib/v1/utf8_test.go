@@ -0,0 +1,206 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var invalidUTF8Strings = []string{
+	"\xff",
+	"a\xffb",
+	"\xc3",
+	"\xc3(",
+	"\xe2\x82",
+	"\xed\xa0\x80",
+	"\xf0\x28\x8c\xbc",
+	"ok é 世界 \xfe\xfe end",
+}
+
+func TestWriteJsonUTF8(t *testing.T) {
+	for _, s := range invalidUTF8Strings {
+		var buf Buffer
+		WriteJsonString(&buf, s)
+		// encoding/json writes U+FFFD raw or escaped depending on the Go
+		// version, so compare the decoded strings.
+		expected, _ := json.Marshal(s)
+		var es, gs string
+		json.Unmarshal(expected, &es)
+		if err := json.Unmarshal(buf.Bytes(), &gs); err != nil || gs != es || buf.Err() != nil {
+			t.Fatalf("Replace %q\nExpected: %s\nGot: %s (%v)", s, expected, buf.String(), buf.Err())
+		}
+
+		buf.Reset()
+		buf.SetUTF8(UTF8Pass)
+		WriteJsonString(&buf, s)
+		if buf.String() != `"`+s+`"` || buf.Err() != nil {
+			t.Fatalf("Pass %q: got %q (%v)", s, buf.String(), buf.Err())
+		}
+
+		buf.Reset()
+		buf.SetUTF8(UTF8Reject)
+		WriteJsonString(&buf, s)
+		if _, ok := buf.Err().(*InvalidUTF8Error); !ok {
+			t.Fatalf("Reject %q: expected *InvalidUTF8Error, got %v", s, buf.Err())
+		}
+		buf.Reset()
+		if buf.Err() != nil {
+			t.Fatalf("Reset did not clear Err")
+		}
+	}
+
+	var buf Buffer
+	buf.SetUTF8(UTF8Reject)
+	WriteJsonString(&buf, "ab\xffc\xfe")
+	if e, ok := buf.Err().(*InvalidUTF8Error); !ok || e.Offset != 2 {
+		t.Fatalf("Expected the first invalid byte at 2, got %v", buf.Err())
+	}
+}
+
+func lexUTF8(data []byte, p UTF8) (string, error) {
+	fs := NewFFLexer(data)
+	fs.UTF8 = p
+	if tok := fs.Scan(); tok != FFTok_string {
+		return "", fs.WrapErr(fs.BigError)
+	}
+	return fs.Output.String(), nil
+}
+
+func TestLexUTF8(t *testing.T) {
+	for _, s := range invalidUTF8Strings {
+		for _, data := range []string{`"` + s + `"`, `"\n` + s + `\t"`} {
+			var expected string
+			if err := json.Unmarshal([]byte(data), &expected); err != nil {
+				t.Fatalf("json.Unmarshal %q: %v", data, err)
+			}
+			got, err := lexUTF8([]byte(data), UTF8Replace)
+			if err != nil || got != expected {
+				t.Fatalf("Replace %q\nExpected: %q\nGot: %q (%v)", data, expected, got, err)
+			}
+
+			got, err = lexUTF8([]byte(data), UTF8Pass)
+			if err != nil {
+				t.Fatalf("Pass %q: %v", data, err)
+			}
+			if data[1] != '\\' && got != s {
+				t.Fatalf("Pass %q: got %q", data, got)
+			}
+
+			_, err = lexUTF8([]byte(data), UTF8Reject)
+			le, ok := err.(*LexerError)
+			if !ok {
+				t.Fatalf("Reject %q: expected *LexerError, got %v", data, err)
+			}
+			if _, ok := le.err.(*InvalidUTF8Error); !ok || !PassError(err) {
+				t.Fatalf("Reject %q: expected *InvalidUTF8Error, got %v", data, le.err)
+			}
+		}
+	}
+
+	_, err := lexUTF8([]byte("\"ab\\\"\xffc\""), UTF8Reject)
+	le := err.(*LexerError)
+	if e := le.err.(*InvalidUTF8Error); e.Offset != 4 || le.offset != 5 {
+		t.Fatalf("Expected the invalid byte at 4 in the string, 5 in the input, got %v at %d", e, le.offset)
+	}
+
+	// Valid follows the default policy, which accepts invalid UTF-8 like
+	// json.Valid.
+	for _, data := range []string{"[\"\xff\"]", `["\ud800"]`} {
+		if (Valid([]byte(data)) == nil) != json.Valid([]byte(data)) {
+			t.Fatalf("Valid(%q): %v, json.Valid: %v", data, Valid([]byte(data)), json.Valid([]byte(data)))
+		}
+	}
+}
+
+func TestLexUTF8Surrogates(t *testing.T) {
+	tests := []struct {
+		data   string
+		offset int    // of the lone surrogate in the string, -1 if none
+		pass   string // decoded under UTF8Pass
+	}{
+		{`"\ud83d\ude00"`, -1, "\U0001F600"},
+		{`"\ud800"`, 0, "\xed\xa0\x80"},
+		{`"ab\udfff"`, 2, "ab\xed\xbf\xbf"},
+		{`"\n\ud800\u0041"`, 2, "\n\xed\xa0\x80A"},
+		{`"\udc00\ud800x"`, 0, "\xed\xb0\x80\xed\xa0\x80x"},
+		{`"\ud800\ud800\udc00"`, 0, "\xed\xa0\x80\U00010000"},
+	}
+
+	for _, test := range tests {
+		var expected string
+		if err := json.Unmarshal([]byte(test.data), &expected); err != nil {
+			t.Fatalf("json.Unmarshal %q: %v", test.data, err)
+		}
+		got, err := lexUTF8([]byte(test.data), UTF8Replace)
+		if err != nil || got != expected {
+			t.Fatalf("Replace %q\nExpected: %q\nGot: %q (%v)", test.data, expected, got, err)
+		}
+
+		got, err = lexUTF8([]byte(test.data), UTF8Pass)
+		if err != nil || got != test.pass {
+			t.Fatalf("Pass %q\nExpected: %q\nGot: %q (%v)", test.data, test.pass, got, err)
+		}
+
+		got, err = lexUTF8([]byte(test.data), UTF8Reject)
+		if test.offset < 0 {
+			if err != nil || got != expected {
+				t.Fatalf("Reject %q\nExpected: %q\nGot: %q (%v)", test.data, expected, got, err)
+			}
+			continue
+		}
+		le, ok := err.(*LexerError)
+		if !ok {
+			t.Fatalf("Reject %q: expected *LexerError, got %v", test.data, err)
+		}
+		if e, ok := le.err.(*InvalidUTF8Error); !ok || e.Offset != test.offset || le.offset != test.offset+1 {
+			t.Fatalf("Reject %q: expected *InvalidUTF8Error at %d, got %v at %d", test.data, test.offset, le.err, le.offset)
+		}
+	}
+}
+
+// Single-quoted strings of the relaxed mode follow the policy like
+// double-quoted ones.
+func TestLexUTF8SingleQuoted(t *testing.T) {
+	data := append([]string{`\ud800`, `ab\udfff`, `\ud83d\ude00`, "é"}, invalidUTF8Strings...)
+	for _, s := range data {
+		for _, p := range []UTF8{UTF8Replace, UTF8Pass, UTF8Reject} {
+			expected, expectedErr := lexUTF8([]byte(`"`+s+`"`), p)
+
+			fs := NewFFLexer([]byte(`'` + s + `'`))
+			fs.Relaxed = true
+			fs.UTF8 = p
+			if tok := fs.Scan(); tok != FFTok_string {
+				if expectedErr == nil {
+					t.Fatalf("%v %q: %v", p, s, fs.WrapErr(fs.BigError))
+				}
+				le, ok := fs.WrapErr(fs.BigError).(*LexerError)
+				if !ok {
+					t.Fatalf("%v %q: expected *LexerError, got %v", p, s, fs.WrapErr(fs.BigError))
+				}
+				if e, ok := le.err.(*InvalidUTF8Error); !ok || *e != *expectedErr.(*LexerError).err.(*InvalidUTF8Error) {
+					t.Fatalf("%v %q: expected %v, got %v", p, s, expectedErr, le.err)
+				}
+				continue
+			}
+			if expectedErr != nil || fs.Output.String() != expected {
+				t.Fatalf("%v %q\nExpected: %q (%v)\nGot: %q", p, s, expected, expectedErr, fs.Output.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+// Valid checks that data holds exactly one JSON value, with nothing but
+// whitespace after it, following the rules of FFLexer.Strict. Strings are
+// read under the default UTF8 policy, so invalid UTF-8 is accepted, as by
+// json.Valid. Errors are *LexerError values carrying the position. Token
+// values are not kept while scanning, so nothing is copied.
+func Valid(data []byte) error {
+	fs := NewFFLexer(data)
+	fs.Output = discardBuffer{}
+	fs.Strict = true
+
+	tok := fs.Scan()
+	switch tok {
+	case FFTok_error, FFTok_eof:
+		return interfaceTokenError(fs, tok)
+	}
+	if err := fs.SkipField(tok); err != nil {
+		return fs.WrapErr(err)
+	}
+	return fs.ScanEOF()
+}
+
+// discardBuffer is a lexer Output dropping every write, for scanning
+// without keeping token values.
+type discardBuffer struct{}
+
+func (discardBuffer) Read(p []byte) (int, error)        { return 0, nil }
+func (discardBuffer) Write(p []byte) (int, error)       { return len(p), nil }
+func (discardBuffer) WriteByte(c byte) error            { return nil }
+func (discardBuffer) WriteString(s string) (int, error) { return len(s), nil }
+func (discardBuffer) WriteRune(r rune) (int, error)     { return 0, nil }
+func (discardBuffer) Truncate(n int)                    {}
+func (discardBuffer) Reset()                            {}
+func (discardBuffer) Grow(n int)                        {}
+func (discardBuffer) Bytes() []byte                     { return nil }
+func (discardBuffer) String() string                    { return "" }
+func (discardBuffer) Len() int                          { return 0 }
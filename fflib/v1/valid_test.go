@@ -0,0 +1,48 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []string{
+		`{}`, `[]`, `"x"`, `-1.5e3`, ` true `, `null`,
+		`{"a":[1,{"b":null},[]],"c":{"d":"é"}}`,
+		`[[[[{"a":{}}]]]]`,
+		``, ` `, `{`, `[1,]`, `[1 2]`, `{"a":1,}`, `{"a" 1}`, `{1:2}`, `{"a":1]`, `[1}`,
+		`{"a":1} {"b":2}`, `1 x`, `[1] /* c */`, `[01]`, `["a\x"]`, `{"a":tru}`, `nul`, `x`,
+		// Escaped surrogates, paired or not.
+		`"\ud83d\ude00"`, `"\ud800"`, `"\ud800x"`, `"\ud800\u0041"`, `"\udc00\ud800"`,
+		`"\ud800\ud800\udc00"`, `["\udfff",1]`, `{"\ud800":1}`, `"\ud800\u12"`, `"\ud800\`, `"\ud800\u`,
+	}
+
+	for _, test := range tests {
+		err := Valid([]byte(test))
+		if (err == nil) != json.Valid([]byte(test)) {
+			t.Fatalf("Valid(%q): %v, json.Valid: %v", test, err, json.Valid([]byte(test)))
+		}
+		if err != nil {
+			if _, ok := err.(*LexerError); !ok {
+				t.Fatalf("Valid(%q): expected a *LexerError, got: %T %v", test, err, err)
+			}
+		}
+	}
+}
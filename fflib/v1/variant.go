@@ -0,0 +1,301 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrVariantKey is returned when a variant object lacks its discriminator,
+// or the discriminator is not a string.
+var ErrVariantKey = errors.New("ffjson: variant discriminator missing")
+
+// UnknownVariantError is returned for a variant name or a concrete type
+// that is not registered for an interface.
+type UnknownVariantError struct {
+	Interface reflect.Type
+	Name      string       // the discriminator read, when decoding
+	Type      reflect.Type // the concrete type, when encoding
+}
+
+func (e *UnknownVariantError) Error() string {
+	if e.Type != nil {
+		return fmt.Sprintf("ffjson: %v is not a registered variant of %v", e.Type, e.Interface)
+	}
+	return fmt.Sprintf("ffjson: unknown variant %q of %v", e.Name, e.Interface)
+}
+
+type variantMarshaler interface {
+	MarshalJSONBuf(buf EncodingBuffer) error
+}
+
+type variantUnmarshaler interface {
+	UnmarshalJSONFFLexer(l *FFLexer, state FFParseState) error
+}
+
+// variantSet holds the variants registered for one interface type.
+type variantSet struct {
+	key   string
+	names map[string]reflect.Type
+	types map[reflect.Type]string
+}
+
+var variants = struct {
+	sync.RWMutex
+	m map[reflect.Type]*variantSet
+}{m: make(map[reflect.Type]*variantSet)}
+
+// RegisterVariant registers the type of proto as the variant called name
+// of the interface iface points to, as in
+//
+//	RegisterVariant((*Shape)(nil), "kind", "circle", Circle{})
+//
+// Values are encoded as JSON objects whose member key holds name. Decoded
+// values have the type of proto, so register &Circle{} to get pointers.
+// All variants of an interface must use the same key.
+func RegisterVariant(iface interface{}, key string, name string, proto interface{}) {
+	it := reflect.TypeOf(iface)
+	if it == nil || it.Kind() != reflect.Ptr || it.Elem().Kind() != reflect.Interface {
+		panic("ffjson: RegisterVariant needs a pointer to an interface, like (*Shape)(nil)")
+	}
+	it = it.Elem()
+
+	t := reflect.TypeOf(proto)
+	if t == nil || !t.Implements(it) {
+		panic(fmt.Sprintf("ffjson: %v does not implement %v", t, it))
+	}
+
+	variants.Lock()
+	defer variants.Unlock()
+
+	vs := variants.m[it]
+	if vs == nil {
+		vs = &variantSet{
+			key:   key,
+			names: make(map[string]reflect.Type),
+			types: make(map[reflect.Type]string),
+		}
+		variants.m[it] = vs
+	}
+	if vs.key != key {
+		panic(fmt.Sprintf("ffjson: variants of %v registered with keys %q and %q", it, vs.key, key))
+	}
+	if _, ok := vs.names[name]; ok {
+		panic(fmt.Sprintf("ffjson: variant %q of %v registered twice", name, it))
+	}
+	vs.names[name] = t
+	vs.types[t] = name
+}
+
+func lookupVariants(it reflect.Type) *variantSet {
+	variants.RLock()
+	vs := variants.m[it]
+	variants.RUnlock()
+	return vs
+}
+
+// WriteVariant writes the value of the interface ptr points to (a *Shape)
+// as a JSON object starting with the discriminator. An empty key uses the
+// registered one.
+func WriteVariant(buf EncodingBuffer, ptr interface{}, key string) error {
+	pv := reflect.ValueOf(ptr).Elem()
+	if pv.IsNil() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	v := pv.Elem()
+	vs := lookupVariants(pv.Type())
+	name, ok := "", false
+	if vs != nil {
+		name, ok = vs.types[v.Type()]
+		if !ok && v.Kind() == reflect.Ptr {
+			name, ok = vs.types[v.Type().Elem()]
+		} else if !ok {
+			name, ok = vs.types[reflect.PtrTo(v.Type())]
+		}
+	}
+	if !ok {
+		return &UnknownVariantError{Interface: pv.Type(), Type: v.Type()}
+	}
+	if key == "" {
+		key = vs.key
+	}
+
+	// Generated methods have pointer receivers.
+	val := v.Interface()
+	if v.Kind() != reflect.Ptr {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		val = p.Interface()
+	}
+
+	// The object is written to tmp to insert the discriminator, with the
+	// policies of buf.
+	tmp := newBufferLike(buf)
+	var err error
+	if m, ok := val.(variantMarshaler); ok {
+		err = m.MarshalJSONBuf(tmp)
+	} else {
+		err = tmp.Encode(val)
+	}
+	if err == nil {
+		err = tmp.Err()
+	}
+	if err != nil {
+		return err
+	}
+	obj := tmp.Bytes()
+
+	obj = bytes.TrimSpace(obj)
+	if len(obj) < 2 || obj[0] != '{' {
+		return fmt.Errorf("ffjson: variant %v does not encode as a JSON object", v.Type())
+	}
+	obj = bytes.TrimSpace(obj[1:])
+
+	buf.WriteByte('{')
+	WriteJsonString(buf, key)
+	buf.WriteByte(':')
+	WriteJsonString(buf, name)
+	if obj[0] != '}' {
+		buf.WriteByte(',')
+	}
+	buf.Write(obj)
+	return nil
+}
+
+// ReadVariant decodes the value starting with tok into the interface ptr
+// points to (a *Shape), using the concrete type registered for its
+// discriminator. An empty key uses the registered one. JSON null sets the
+// interface to nil.
+func ReadVariant(fs *FFLexer, tok FFTok, ptr interface{}, key string) error {
+	pv := reflect.ValueOf(ptr).Elem()
+	if tok == FFTok_null {
+		pv.Set(reflect.Zero(pv.Type()))
+		return nil
+	}
+	if tok != FFTok_left_bracket {
+		return fmt.Errorf("ffjson: cannot unmarshal %v into variant of %v", tok, pv.Type())
+	}
+
+	raw, err := fs.CaptureField(tok)
+	if err != nil {
+		return err
+	}
+
+	vs := lookupVariants(pv.Type())
+	if vs == nil {
+		return &UnknownVariantError{Interface: pv.Type()}
+	}
+	if key == "" {
+		key = vs.key
+	}
+
+	name, err := variantName(fs.sub(raw), key)
+	if err != nil {
+		return subErr(err)
+	}
+	t, ok := vs.names[name]
+	if !ok {
+		return &UnknownVariantError{Interface: pv.Type(), Name: name}
+	}
+
+	et := t
+	if t.Kind() == reflect.Ptr {
+		et = t.Elem()
+	}
+	p := reflect.New(et)
+	if u, ok := p.Interface().(variantUnmarshaler); ok {
+		err = u.UnmarshalJSONFFLexer(fs.sub(raw), FFParse_map_start)
+	} else {
+		err = json.Unmarshal(raw, p.Interface())
+	}
+	if err != nil {
+		return subErr(err)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		pv.Set(p)
+	} else {
+		pv.Set(p.Elem())
+	}
+	return nil
+}
+
+// subErr unwraps the *LexerError of a sub-lexer, whose position is in the
+// variant alone, so fs.WrapErr places the error in the whole input.
+func subErr(err error) error {
+	if le, ok := err.(*LexerError); ok {
+		return le.err
+	}
+	return err
+}
+
+// variantName returns the string member key of the JSON object read by fs.
+// Unless fs.DuplicateKeys is DuplicateKeysAllowed, the whole object is read
+// and a key appearing twice is a *DuplicateKeyError.
+func variantName(fs *FFLexer, key string) (string, error) {
+	if fs.Scan() != FFTok_left_bracket {
+		return "", ErrVariantKey
+	}
+
+	name, found := "", false
+	for {
+		tok := fs.Scan()
+		switch tok {
+		case FFTok_right_bracket:
+			if found {
+				return name, nil
+			}
+			return "", ErrVariantKey
+		case FFTok_comma:
+			continue
+		case FFTok_string:
+		default:
+			return "", ErrVariantKey
+		}
+
+		isKey := string(fs.Output.Bytes()) == key
+		if isKey && found {
+			return "", &DuplicateKeyError{Key: key}
+		}
+		if fs.Scan() != FFTok_colon {
+			return "", ErrVariantKey
+		}
+
+		tok = fs.Scan()
+		if isKey {
+			if tok != FFTok_string {
+				return "", ErrVariantKey
+			}
+			name, found = fs.Output.String(), true
+			if fs.DuplicateKeys == DuplicateKeysAllowed {
+				return name, nil
+			}
+			continue
+		}
+		if err := fs.SkipField(tok); err != nil {
+			return "", err
+		}
+	}
+}
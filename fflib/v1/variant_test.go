@@ -0,0 +1,163 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+)
+
+type variantShape interface {
+	Area() float64
+}
+
+type variantCircle struct {
+	R float64 `json:"r"`
+}
+
+func (c variantCircle) Area() float64 { return 3 * c.R * c.R }
+
+type variantSquare struct{}
+
+func (s *variantSquare) Area() float64 { return 1 }
+
+func init() {
+	RegisterVariant((*variantShape)(nil), "kind", "circle", variantCircle{})
+	RegisterVariant((*variantShape)(nil), "kind", "square", &variantSquare{})
+}
+
+func TestWriteVariant(t *testing.T) {
+	var testvecs = []struct {
+		v        variantShape
+		key      string
+		expected string
+	}{
+		{variantCircle{R: 2}, "", `{"kind":"circle","r":2}`},
+		{&variantCircle{R: 2}, "type", `{"type":"circle","r":2}`},
+		{&variantSquare{}, "", `{"kind":"square"}`},
+		{nil, "", `null`},
+	}
+
+	for _, v := range testvecs {
+		var buf Buffer
+		err := WriteVariant(&buf, &v.v, v.key)
+		if err != nil {
+			t.Fatalf("WriteVariant(%v): %v", v.v, err)
+		}
+		if buf.String() != v.expected {
+			t.Fatalf("Expected: %v\nGot: %v", v.expected, buf.String())
+		}
+	}
+}
+
+func TestReadVariant(t *testing.T) {
+	var s variantShape
+
+	fs := NewFFLexer([]byte(`{"r":2,"kind":"circle"}`))
+	if err := ReadVariant(fs, fs.Scan(), &s, ""); err != nil {
+		t.Fatalf("ReadVariant: %v", err)
+	}
+	if c, ok := s.(variantCircle); !ok || c.R != 2 {
+		t.Fatalf("Expected circle, got: %#v", s)
+	}
+
+	fs = NewFFLexer([]byte(`{"kind":"square"}`))
+	if err := ReadVariant(fs, fs.Scan(), &s, ""); err != nil {
+		t.Fatalf("ReadVariant: %v", err)
+	}
+	if _, ok := s.(*variantSquare); !ok {
+		t.Fatalf("Expected *square, got: %#v", s)
+	}
+
+	fs = NewFFLexer([]byte(`null`))
+	if err := ReadVariant(fs, fs.Scan(), &s, ""); err != nil || s != nil {
+		t.Fatalf("Expected nil, got: %#v %v", s, err)
+	}
+
+	fs = NewFFLexer([]byte(`{"kind":"triangle"}`))
+	if _, ok := ReadVariant(fs, fs.Scan(), &s, "").(*UnknownVariantError); !ok {
+		t.Fatalf("Expected UnknownVariantError")
+	}
+
+	fs = NewFFLexer([]byte(`{"r":1}`))
+	if err := ReadVariant(fs, fs.Scan(), &s, ""); err != ErrVariantKey {
+		t.Fatalf("Expected ErrVariantKey, got: %v", err)
+	}
+}
+
+type variantLabel struct {
+	Label string `json:"label"`
+}
+
+func (l variantLabel) Area() float64 { return 0 }
+
+func init() {
+	RegisterVariant((*variantShape)(nil), "kind", "label", variantLabel{})
+}
+
+func TestWriteVariantPolicies(t *testing.T) {
+	var s variantShape = variantLabel{Label: "é<"}
+
+	var buf Buffer
+	buf.SetEscape(EscapeASCII)
+	if err := WriteVariant(&buf, &s, ""); err != nil {
+		t.Fatalf("WriteVariant: %v", err)
+	}
+	expected := `{"kind":"label","label":"\u00e9\u003c"}`
+	if buf.String() != expected {
+		t.Fatalf("Expected: %v\nGot: %v", expected, buf.String())
+	}
+}
+
+func TestSubLexer(t *testing.T) {
+	fs := NewFFLexer([]byte(`[[{}]]`))
+	fs.UseNumber = true
+	fs.Include = NewFieldSet("a")
+	fs.Strict = true
+	fs.Limits = Limits{MaxDepth: 3}
+	fs.DuplicateKeys = DuplicateKeysAll
+	fs.UTF8 = UTF8Reject
+	fs.Scan()
+	fs.Scan()
+
+	sub := fs.sub([]byte(`{"a":{}}`))
+	if !sub.UseNumber || sub.Include == nil || !sub.Strict || sub.Limits != fs.Limits ||
+		sub.DuplicateKeys != fs.DuplicateKeys || sub.UTF8 != fs.UTF8 || sub.depth != fs.depth {
+		t.Fatalf("Expected the settings of the lexer, got: %#v", sub)
+	}
+	sub.Scan()
+	sub.Scan()
+	sub.Scan()
+	if tok := sub.Scan(); tok != FFTok_error || !IsLimitError(sub.WrapErr(sub.BigError)) {
+		t.Fatalf("Expected the depth limit to cover both lexers, got: %v %v", tok, sub.BigError)
+	}
+}
+
+func TestReadVariantDuplicateKey(t *testing.T) {
+	var s variantShape
+
+	fs := NewFFLexer([]byte(`{"kind":"circle","kind":"square"}`))
+	if err := ReadVariant(fs, fs.Scan(), &s, ""); err != nil {
+		t.Fatalf("ReadVariant: %v", err)
+	}
+
+	fs = NewFFLexer([]byte(`{"kind":"circle","kind":"square"}`))
+	fs.DuplicateKeys = DuplicateKeysKnown
+	if _, ok := ReadVariant(fs, fs.Scan(), &s, "").(*DuplicateKeyError); !ok {
+		t.Fatalf("Expected *DuplicateKeyError")
+	}
+}
@@ -20,6 +20,7 @@ package ffjsoninception
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/yingshengtech/ffjson/shared"
@@ -66,6 +67,25 @@ func handleField(ic *Inception, name, jsonName string, typ reflect.Type, ptr boo
 	return handleFieldAddr(ic, name, jsonName, false, typ, ptr, quoted)
 }
 
+// handleStructField is handleField for the struct field f, honoring the
+// options of its ffjson tag.
+func handleStructField(ic *Inception, name string, f *StructField) string {
+	if useTimeFormat(f.Typ, f.TimeFormat) {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v time*/\n", name, f.Typ, f.Typ.Kind())
+		return out + getTimeHandler(ic, name, f.JsonName, f.Typ, f.Pointer, f.TimeFormat)
+	}
+	if f.Variant {
+		out := fmt.Sprintf("/* handler: %s type=%v kind=%v variant*/\n", name, f.Typ, f.Typ.Kind())
+		return out + tplStr(decodeTpl["handleVariant"], handleVariant{
+			Name:     name,
+			JsonName: f.JsonName,
+			Typ:      f.Typ,
+			Key:      f.VariantKey,
+		})
+	}
+	return handleField(ic, name, f.JsonName, f.Typ, f.Pointer, f.ForceString)
+}
+
 func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ reflect.Type, ptr bool, quoted bool) string {
 	autoImport(ic, typ)
 
@@ -80,6 +100,10 @@ func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ re
 
 	out := fmt.Sprintf("/* handler: %s type=%v kind=%v quoted=%t*/\n", name, typ, typ.Kind(), quoted)
 
+	if typ == timeType {
+		return out + getTimeHandler(ic, name, jsonName, typ, takeAddr || ptr, timeFormat{})
+	}
+
 	umlx := typ.Implements(unmarshalFasterType) || typeInInception(ic, typ, shared.MustDecoder)
 	umlx = umlx || reflect.PtrTo(typ).Implements(unmarshalFasterType)
 
@@ -100,6 +124,17 @@ func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ re
 		return out
 	}
 
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		out += tplStr(decodeTpl["handleTextUnmarshaler"], handleTextUnmarshaler{
+			IC:       ic,
+			Name:     name,
+			JsonName: jsonName,
+			Typ:      typ,
+			TakeAddr: takeAddr || ptr,
+		})
+		return out
+	}
+
 	// TODO(pquerna): generic handling of token type mismatching struct type
 	switch typ.Kind() {
 	case reflect.Int,
@@ -117,7 +152,8 @@ func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ re
 		reflect.Uint8,
 		reflect.Uint16,
 		reflect.Uint32,
-		reflect.Uint64:
+		reflect.Uint64,
+		reflect.Uintptr:
 
 		allowed := buildTokens(quoted, "FFTok_string", "FFTok_integer", "FFTok_null")
 		out += getAllowTokens(typ.Name(), jsonName, allowed...)
@@ -181,14 +217,43 @@ func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ re
 				Quoted:   quoted,
 			})
 		}
+	case reflect.Struct:
+		if typ.Name() == "" {
+			ic.OutputImports[`"bytes"`] = true
+			out += tplStr(decodeTpl["handleInlineStruct"], handleInlineStruct{
+				IC:       ic,
+				Name:     name,
+				JsonName: jsonName,
+				Typ:      typ,
+				TakeAddr: takeAddr || ptr,
+				Fields:   extractFields(reflect.Indirect(reflect.New(typ)).Interface()),
+			})
+		} else {
+			ic.OutputImports[`"encoding/json"`] = true
+			out += tplStr(decodeTpl["handleFallback"], handleFallback{
+				Name:     name,
+				JsonName: jsonName,
+				Typ:      typ,
+				Kind:     typ.Kind(),
+			})
+		}
 	case reflect.Interface:
-		ic.OutputImports[`"encoding/json"`] = true
-		out += tplStr(decodeTpl["handleFallback"], handleFallback{
-			Name:     name,
-			JsonName: jsonName,
-			Typ:      typ,
-			Kind:     typ.Kind(),
-		})
+		if typ.NumMethod() == 0 {
+			out += tplStr(decodeTpl["handleInterface"], handleInterface{
+				Name:     name,
+				JsonName: jsonName,
+				Typ:      typ,
+				TakeAddr: takeAddr || ptr,
+			})
+		} else {
+			ic.OutputImports[`"encoding/json"`] = true
+			out += tplStr(decodeTpl["handleFallback"], handleFallback{
+				Name:     name,
+				JsonName: jsonName,
+				Typ:      typ,
+				Kind:     typ.Kind(),
+			})
+		}
 	case reflect.Map:
 		out += tplStr(decodeTpl["handleObject"], handleObject{
 			IC:       ic,
@@ -211,6 +276,37 @@ func handleFieldAddr(ic *Inception, name, jsonName string, takeAddr bool, typ re
 	return out
 }
 
+// handleMapKey decodes an object key into name. As in encoding/json,
+// encoding.TextUnmarshaler takes precedence, integer kinds are parsed from
+// the key string and all other keys are decoded as plain strings.
+func handleMapKey(ic *Inception, name, jsonName string, typ reflect.Type) string {
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return tplStr(decodeTpl["handleTextKey"], handleTextKey{
+			Name:     name,
+			JsonName: jsonName,
+			Typ:      typ,
+		})
+	}
+
+	switch typ.Kind() {
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		out := getAllowTokens(typ.Name(), jsonName, "FFTok_string")
+		return out + handleField(ic, name, jsonName, typ, false, true)
+	}
+
+	return handleField(ic, name, jsonName, typ, false, false)
+}
+
 func getArrayHandler(ic *Inception, name, jsonName string, typ reflect.Type, ptr bool) string {
 	if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
 		ic.OutputImports[`"encoding/base64"`] = true
@@ -302,12 +398,93 @@ func getType(ic *Inception, name string, typ reflect.Type) string {
 	}
 
 	if s == "" {
-		return typ.String()
+		return getTypeName(ic, typ)
 	}
 
 	return s
 }
 
+// getTypeName renders typ as Go source, also for unnamed composite types
+// whose elements are named types of the package being generated.
+func getTypeName(ic *Inception, typ reflect.Type) string {
+	if typ.Name() != "" {
+		return getType(ic, "", typ)
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return "*" + getTypeName(ic, typ.Elem())
+	case reflect.Slice:
+		return "[]" + getTypeName(ic, typ.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]", typ.Len()) + getTypeName(ic, typ.Elem())
+	case reflect.Map:
+		return "map[" + getTypeName(ic, typ.Key()) + "]" + getTypeName(ic, typ.Elem())
+	case reflect.Struct:
+		// Inline struct, whose fields may have named types of the package.
+		fields := make([]string, typ.NumField())
+		for i := range fields {
+			sf := typ.Field(i)
+			f := getTypeName(ic, sf.Type)
+			if !sf.Anonymous {
+				f = sf.Name + " " + f
+			}
+			if sf.Tag != "" {
+				f += " " + strconv.Quote(string(sf.Tag))
+			}
+			fields[i] = f
+		}
+		if len(fields) == 0 {
+			return "struct{}"
+		}
+		return "struct { " + strings.Join(fields, "; ") + " }"
+	}
+
+	return typ.String()
+}
+
+// decodeFuncKey identifies the functions added by getDecodeFunc: values of
+// the same type with the same ffjson tag options share one function.
+type decodeFuncKey struct {
+	Typ        reflect.Type
+	Ptr        bool
+	Quoted     bool
+	TimeFormat timeFormat
+	Variant    bool
+	VariantKey string
+}
+
+// getDecodeFunc returns the name of a function decoding the next JSON value
+// of a lexer into a f.Typ (or *f.Typ when f.Pointer is set) using the
+// regular field handlers. The function is added on first use, named after
+// the struct si that needs it first.
+func getDecodeFunc(ic *Inception, si *StructInfo, f *StructField) string {
+	key := decodeFuncKey{
+		Typ:        f.Typ,
+		Ptr:        f.Pointer,
+		Quoted:     f.ForceString,
+		TimeFormat: f.TimeFormat,
+		Variant:    f.Variant,
+		VariantKey: f.VariantKey,
+	}
+	if name, ok := ic.decodeFuncs[key]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("ffj_decode_%s_%d", si.Name, len(ic.decodeFuncs))
+	ic.decodeFuncs[key] = name
+
+	// The JSON name in error messages is an argument of the function.
+	field := *f
+	field.JsonName = "jsonName"
+	ic.OutputFuncs = append(ic.OutputFuncs, tplStr(decodeTpl["decodeValue"], decodeValue{
+		IC:       ic,
+		FuncName: name,
+		Field:    &field,
+	}))
+	return name
+}
+
 func buildTokens(containsOptional bool, optional string, required ...string) []string {
 	if containsOptional {
 		return append(required, optional)
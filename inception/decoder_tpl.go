@@ -31,19 +31,26 @@ func init() {
 	decodeTpl = make(map[string]*template.Template)
 
 	funcs := map[string]string{
-		"handlerNumeric":    handlerNumericTxt,
-		"allowTokens":       allowTokensTxt,
-		"handleFallback":    handleFallbackTxt,
-		"handleString":      handleStringTxt,
-		"handleObject":      handleObjectTxt,
-		"handleArray":       handleArrayTxt,
-		"handleSlice":       handleSliceTxt,
-		"handleByteSlice":   handleByteSliceTxt,
-		"handleBool":        handleBoolTxt,
-		"handlePtr":         handlePtrTxt,
-		"header":            headerTxt,
-		"ujFunc":            ujFuncTxt,
-		"handleUnmarshaler": handleUnmarshalerTxt,
+		"handlerNumeric":        handlerNumericTxt,
+		"allowTokens":           allowTokensTxt,
+		"handleFallback":        handleFallbackTxt,
+		"handleString":          handleStringTxt,
+		"handleObject":          handleObjectTxt,
+		"handleArray":           handleArrayTxt,
+		"handleSlice":           handleSliceTxt,
+		"handleByteSlice":       handleByteSliceTxt,
+		"handleBool":            handleBoolTxt,
+		"handlePtr":             handlePtrTxt,
+		"header":                headerTxt,
+		"ujFunc":                ujFuncTxt,
+		"handleUnmarshaler":     handleUnmarshalerTxt,
+		"decodeValue":           decodeValueTxt,
+		"handleInlineStruct":    handleInlineStructTxt,
+		"handleTextKey":         handleTextKeyTxt,
+		"handleTextUnmarshaler": handleTextUnmarshalerTxt,
+		"handleTime":            handleTimeTxt,
+		"handleVariant":         handleVariantTxt,
+		"handleInterface":       handleInterfaceTxt,
 	}
 
 	tplFuncs := template.FuncMap{
@@ -51,11 +58,14 @@ func init() {
 		"getNumberSize":       getNumberSize,
 		"getType":             getType,
 		"handleField":         handleField,
+		"handleStructField":   handleStructField,
 		"handleFieldAddr":     handleFieldAddr,
 		"unquoteField":        unquoteField,
 		"getTmpVarFor":        getTmpVarFor,
 		"getSetFieldMarkFunc": getSetFieldMarkFunc,
+		"handleMapKey":        handleMapKey,
 		"getFieldType":        getFieldType,
+		"getTypeName":         getTypeName,
 	}
 
 	for k, v := range funcs {
@@ -79,8 +89,10 @@ func getFieldType(typ reflect.Type) string {
 }
 
 func getSetFieldMarkFunc(name string) string {
+	// Only direct fields of the receiver carry a mark; names such as
+	// "uj.Meta.Source" (inline struct) or "pv.Source" (decodeValue) do not.
 	ns := strings.Split(name, ".")
-	if len(ns) != 2 {
+	if len(ns) != 2 || ns[0] != "uj" {
 		return ""
 	}
 
@@ -136,6 +148,9 @@ type allowTokens struct {
 
 var allowTokensTxt = `
 {
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
 	if {{range $index, $element := .Tokens}}{{if ne $index 0 }}&&{{end}} tok != fflib.{{$element}}{{end}} {
 		//return fs.WrapErr(fmt.Errorf("cannot unmarshal %s into Go value for {{.Name}}", tok))
 		return errors.New({{.JsonName}} + "格式错误")
@@ -156,6 +171,9 @@ var handleFallbackTxt = `
 	tbuf, err := fs.CaptureField(tok)
 	if err != nil {
 		//return fs.WrapErr(err)
+		if fflib.PassError(err) {
+			return fs.WrapErr(err)
+		}
 		return errors.New({{.JsonName}} + "格式错误")
 	}
 
@@ -255,6 +273,7 @@ var handleObjectTxt = `
 		{{end}}
 
 		wantVal := true
+		nelem := 0
 
 		for {
 		{{$keyPtr := false}}
@@ -294,7 +313,24 @@ var handleObjectTxt = `
 				wantVal = true
 			}
 
-			{{handleField .IC "k" .JsonName .Typ.Key $keyPtr false}}
+			nelem++
+			if err := fs.LimitElements(nelem); err != nil {
+				return fs.WrapErr(err)
+			}
+
+			{{handleMapKey .IC "k" .JsonName .Typ.Key}}
+
+			if fs.DuplicateKeys == fflib.DuplicateKeysAll {
+				// 键的 token 仍在 fs.Output 中
+				{{if eq .TakeAddr true}}
+				_, dup := tval[k]
+				{{else}}
+				_, dup := {{.Name}}[k]
+				{{end}}
+				if dup {
+					return fs.WrapErr(&fflib.DuplicateKeyError{Key: fs.Output.String()})
+				}
+			}
 
 			// Expect ':' after key
 			tok = fs.Scan()
@@ -324,6 +360,173 @@ var handleObjectTxt = `
 }
 `
 
+type handleTextUnmarshaler struct {
+	IC       *Inception
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	TakeAddr bool
+}
+
+var handleTextUnmarshalerTxt = `
+{
+	{{$ic := .IC}}
+	/* encoding.TextUnmarshaler. type={{printf "%v" .Typ}} */
+	{{getAllowTokens .Typ.Name .JsonName "FFTok_string" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		{{if eq .TakeAddr true}}
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getType $ic .Typ.Name .Typ}})
+		}
+		{{end}}
+		err = {{.Name}}.UnmarshalText(fs.Output.Bytes())
+		if err != nil {
+			// return fs.WrapErr(err)
+			return errors.New({{.JsonName}} + "格式错误")
+		}
+
+		//handleTextUnmarshalerTxt
+		{{getSetFieldMarkFunc .Name}}
+	}
+}
+`
+
+type handleTime struct {
+	IC       *Inception
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	TakeAddr bool
+	Format   timeFormat
+	Assign   string
+}
+
+var handleTimeTxt = `
+{
+	{{$ic := .IC}}
+	/* Time. type={{printf "%v" .Typ}} layout={{printf "%q" .Format.Layout}} unix={{.Format.Unix}} milli={{.Format.Milli}} */
+	{{if eq .Format.Unix true}}
+	{{getAllowTokens .Typ.Name .JsonName "FFTok_integer" "FFTok_null"}}
+	{{else}}
+	{{getAllowTokens .Typ.Name .JsonName "FFTok_string" "FFTok_null"}}
+	{{end}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		{{if eq .Format.Unix true}}
+		tval, err := fflib.ParseUnixTime(fs.Output.Bytes(), {{.Format.Milli}})
+		{{else}}
+		tval, err := fflib.ParseTime(fs.Output.Bytes(), {{printf "%q" .Format.Layout}})
+		{{end}}
+		if err != nil {
+			// return fs.WrapErr(err)
+			return errors.New({{.JsonName}} + "格式错误")
+		}
+		{{if eq .TakeAddr true}}
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getTypeName $ic .Typ}})
+		}
+		{{end}}
+		{{.Assign}}
+
+		//handleTimeTxt
+		{{getSetFieldMarkFunc .Name}}
+	}
+}
+`
+
+type handleInterface struct {
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	TakeAddr bool
+}
+
+var handleInterfaceTxt = `
+{
+	/* interface{}. type={{printf "%v" .Typ}} */
+	tval, err := fflib.DecodeInterface(fs, tok)
+	if err != nil {
+		// return err
+		if fflib.PassError(err) {
+			return fs.WrapErr(err)
+		}
+		return errors.New({{.JsonName}} + "格式错误")
+	}
+	{{if eq .TakeAddr true}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		{{.Name}} = &tval
+	}
+	{{else}}
+	{{.Name}} = tval
+	{{end}}
+
+	//handleInterfaceTxt
+	if tok != fflib.FFTok_null {
+		{{getSetFieldMarkFunc .Name}}
+	}
+}
+`
+
+type handleVariant struct {
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	Key      string
+}
+
+var handleVariantTxt = `
+{
+	/* Variant. type={{printf "%v" .Typ}} key={{printf "%q" .Key}} */
+	{{getAllowTokens .Typ.Name .JsonName "FFTok_left_bracket" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{.Name}} = nil
+	} else {
+		err = fflib.ReadVariant(fs, tok, &{{.Name}}, {{printf "%q" .Key}})
+		if err != nil {
+			// return fs.WrapErr(err)
+			if fflib.PassError(err) {
+				return fs.WrapErr(err)
+			}
+			return errors.New({{.JsonName}} + "格式错误")
+		}
+
+		//handleVariantTxt
+		{{getSetFieldMarkFunc .Name}}
+	}
+}
+`
+
+type handleTextKey struct {
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+}
+
+var handleTextKeyTxt = `
+{
+	/* encoding.TextUnmarshaler key. type={{printf "%v" .Typ}} */
+	if tok != fflib.FFTok_string {
+		// return fs.WrapErr(fmt.Errorf("wanted key token, but got token: %v", tok))
+		return errors.New({{.JsonName}} + "格式错误")
+	}
+
+	err = {{.Name}}.UnmarshalText(fs.Output.Bytes())
+	if err != nil {
+		// return fs.WrapErr(err)
+		return errors.New({{.JsonName}} + "格式错误")
+	}
+}
+`
+
 type handleArray struct {
 	IC              *Inception
 	Name            string
@@ -345,6 +548,7 @@ var handleArrayTxt = `
 	{{end}}
 	if tok != fflib.FFTok_null {
 		wantVal := true
+		nelem := 0
 
 		idx := 0
 		for {
@@ -377,6 +581,11 @@ var handleArrayTxt = `
 				wantVal = true
 			}
 
+			nelem++
+			if err := fs.LimitElements(nelem); err != nil {
+				return fs.WrapErr(err)
+			}
+
 			{{handleField .IC $tmpVar .JsonName .Typ.Elem $ptr false}}
 
 			// Standard json.Unmarshal ignores elements out of array bounds,
@@ -417,6 +626,7 @@ var handleSliceTxt = `
 		{{end}}
 
 		wantVal := true
+		nelem := 0
 
 		for {
 			{{$ptr := false}}
@@ -448,6 +658,11 @@ var handleSliceTxt = `
 				wantVal = true
 			}
 
+			nelem++
+			if err := fs.LimitElements(nelem); err != nil {
+				return fs.WrapErr(err)
+			}
+
 			{{handleField .IC $tmpVar .JsonName .Typ.Elem $ptr false}}
 			{{if eq .IsPtr true}}
 				*{{.Name}} = append(*{{.Name}}, {{$tmpVar}})
@@ -488,6 +703,133 @@ var handleByteSliceTxt = `
 }
 `
 
+type handleInlineStruct struct {
+	IC       *Inception
+	Name     string
+	JsonName string
+	Typ      reflect.Type
+	TakeAddr bool
+	Fields   []*StructField
+}
+
+var handleInlineStructTxt = `
+{
+	{{$ic := .IC}}
+	{{$name := .Name}}
+	{{$keyVar := getTmpVarFor .Name}}
+	/* Inline struct. type={{printf "%v" .Typ}} */
+	{{getAllowTokens .Typ.Name .JsonName "FFTok_left_bracket" "FFTok_null"}}
+	if tok == fflib.FFTok_null {
+		{{if eq .TakeAddr true}}
+		{{.Name}} = nil
+		{{end}}
+	} else {
+		{{if eq .TakeAddr true}}
+		if {{.Name}} == nil {
+			{{.Name}} = new({{getTypeName $ic .Typ}})
+		}
+		{{end}}
+
+		wantVal := true
+		// 已出现的成员，按 fs.DuplicateKeys 拒绝重复的键
+		var seenKeys [{{len .Fields}}]bool
+		var unknownKeys map[string]bool
+
+		for {
+			tok = fs.Scan()
+			if tok == fflib.FFTok_error {
+				goto tokerror
+			}
+			if tok == fflib.FFTok_right_bracket {
+				break
+			}
+
+			if tok == fflib.FFTok_comma {
+				if wantVal == true {
+					// return fs.WrapErr(fmt.Errorf("wanted value token, but got token: %v", tok))
+					return errors.New({{.JsonName}} + "格式错误")
+				}
+				continue
+			} else {
+				wantVal = true
+			}
+
+			if tok != fflib.FFTok_string {
+				// return fs.WrapErr(fmt.Errorf("wanted key token, but got token: %v", tok))
+				return errors.New({{.JsonName}} + "格式错误")
+			}
+
+			{{$keyVar}} := -1
+			kn := fs.Output.Bytes()
+			{{range $index, $field := .Fields}}
+			if {{$keyVar}} < 0 && string(kn) == {{$field.JsonName}} {
+				{{$keyVar}} = {{$index}}
+			}
+			{{end}}
+			{{range $index, $field := .Fields}}
+			if {{$keyVar}} < 0 && bytes.EqualFold(kn, []byte({{$field.JsonName}})) {
+				{{$keyVar}} = {{$index}}
+			}
+			{{end}}
+			if fs.DuplicateKeys != fflib.DuplicateKeysAllowed {
+				if {{$keyVar}} >= 0 {
+					if seenKeys[{{$keyVar}}] {
+						return fs.WrapErr(&fflib.DuplicateKeyError{Key: string(kn)})
+					}
+					seenKeys[{{$keyVar}}] = true
+				} else if fs.DuplicateKeys == fflib.DuplicateKeysAll {
+					if unknownKeys[string(kn)] {
+						return fs.WrapErr(&fflib.DuplicateKeyError{Key: string(kn)})
+					}
+					if unknownKeys == nil {
+						unknownKeys = make(map[string]bool)
+					}
+					unknownKeys[string(kn)] = true
+				}
+			}
+
+			// Expect ':' after key
+			tok = fs.Scan()
+			if tok != fflib.FFTok_colon {
+				// return fs.WrapErr(fmt.Errorf("wanted colon token, but got token: %v", tok))
+				return errors.New({{.JsonName}} + "格式错误")
+			}
+
+			tok = fs.Scan()
+			switch {{$keyVar}} {
+			{{range $index, $field := .Fields}}
+			case {{$index}}:
+				// 同顶层字段，只解析 fs.Include 选中的成员
+				include := fs.Include
+				if include != nil {
+					sub, ok := include[{{$field.JsonName}}]
+					if !ok {
+						err = fs.SkipField(tok)
+						if err != nil {
+							return fs.WrapErr(err)
+						}
+						break
+					}
+					fs.Include = sub
+				}
+				{{handleStructField $ic (printf "%s.%s" $name $field.Name) $field}}
+				fs.Include = include
+			{{end}}
+			default:
+				err = fs.SkipField(tok)
+				if err != nil {
+					return fs.WrapErr(err)
+				}
+			}
+			wantVal = false
+		}
+
+		//handleInlineStructTxt
+		{{getSetFieldMarkFunc .Name}}
+	}
+}
+`
+
 type handleBool struct {
 	Name     string
 	JsonName string
@@ -640,6 +982,10 @@ func (uj *{{$.SI.Name}}) ResetFieldMark() {
 
 //SetFieldMark 设置字段的赋值标识，isMark不传时，默认:true
 func (uj *{{$.SI.Name}}) SetFieldMark(fieldName string, isMark ...bool) {
+	if uj.fieldMark == nil {
+		uj.fieldMark = make(map[string]bool)
+	}
+
 	if len(isMark) == 1 {
 		uj.fieldMark[fieldName] = isMark[0]
 		return
@@ -655,7 +1001,7 @@ func (uj *{{$.SI.Name}}) {{$field.Name}}Mark() bool {
 }
 
 //Set{{$field.Name}} 设置{{$field.Name}}的值，并将赋值标识设为:true
-func (uj *{{$.SI.Name}}) Set{{$field.Name}}(val {{getFieldType .Typ}}) {
+func (uj *{{$.SI.Name}}) Set{{$field.Name}}(val {{if $field.Pointer}}*{{end}}{{getTypeName $ic .Typ}}) {
 	uj.{{$field.Name}} = val
 	uj.SetFieldMark("{{$field.Name}}")
 }
@@ -693,7 +1039,8 @@ func (uj *{{$.SI.Name}}) AutoSetFieldValue(pm map[string]string) error {
 			{{range $index, $field := $si.Fields}}
 			{{if ne $field.JsonName "-"}}
 			case strings.ToLower({{$field.JsonName}}):
-				uj.autoSetFieldValue(&jsonBytes, "{{getFieldType .Typ}}", {{$field.JsonName}}, v)
+				{{/* %q: inline struct types hold quoted tags */}}
+				uj.autoSetFieldValue(&jsonBytes, {{printf "%q" (getFieldType .Typ)}}, {{$field.JsonName}}, v)
 			{{end}}
 			{{end}}
 		}
@@ -710,12 +1057,24 @@ func (uj *{{.SI.Name}}) UnmarshalJSON(input []byte) error {
     return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
 }
 
+//UnmarshalJSONFields 只解析 include 中的字段，其余已知字段直接跳过；include 为 nil 时解析全部字段
+func (uj *{{.SI.Name}}) UnmarshalJSONFields(input []byte, include fflib.FieldSet) error {
+	uj.ResetFieldMark()
+
+	fs := fflib.NewFFLexer(input)
+	fs.Include = include
+	return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)
+}
+
 func (uj *{{.SI.Name}}) UnmarshalJSONFFLexer(fs *fflib.FFLexer, state fflib.FFParseState) error {
 	var err error = nil
 	currentKey := ffj_t_{{.SI.Name}}base
 	_ = currentKey
 	tok := fflib.FFTok_init
 	wantedTok := fflib.FFTok_init
+	// 已出现的键，以 ffj_t_ 常量为下标
+	var seenKeys [{{len .SI.Fields}} + 2]bool
+	var unknownKeys map[string]bool
 
 				{{if eq .ResetFields true}}
 				{{range $index, $field := $si.Fields}}
@@ -765,8 +1124,7 @@ mainparse:
 			if len(kn) <= 0 {
 				// "" case. hrm.
 				currentKey = ffj_t_{{.SI.Name}}no_such_key
-				state = fflib.FFParse_want_colon
-				goto mainparse
+				goto keyfound
 			} else {
 				switch kn[0] {
 				{{range $byte, $fields := $si.FieldsByFirstByte}}
@@ -774,23 +1132,41 @@ mainparse:
 					{{range $index, $field := $fields}}
 						{{if ne $index 0 }}} else if {{else}}if {{end}} bytes.Equal(ffj_key_{{$si.Name}}_{{$field.Name}}, kn) {
 						currentKey = ffj_t_{{$si.Name}}_{{$field.Name}}
-						state = fflib.FFParse_want_colon
-						goto mainparse
+						goto keyfound
 					{{end}} }
 				{{end}}
 				}
 				{{range $index, $field := $si.ReverseFields}}
 				if {{$field.FoldFuncName}}(ffj_key_{{$si.Name}}_{{$field.Name}}, kn) {
 					currentKey = ffj_t_{{$si.Name}}_{{$field.Name}}
-					state = fflib.FFParse_want_colon
-					goto mainparse
+					goto keyfound
 				}
 				{{end}}
 				currentKey = ffj_t_{{.SI.Name}}no_such_key
-				state = fflib.FFParse_want_colon
-				goto mainparse
+				goto keyfound
 			}
 
+		keyfound:
+			if fs.DuplicateKeys != fflib.DuplicateKeysAllowed {
+				// 按 fs.DuplicateKeys 拒绝重复的键，忽略大小写匹配到同一字段的键也算重复
+				if currentKey != ffj_t_{{.SI.Name}}no_such_key {
+					if seenKeys[currentKey] {
+						return fs.WrapErr(&fflib.DuplicateKeyError{Key: string(kn)})
+					}
+					seenKeys[currentKey] = true
+				} else if fs.DuplicateKeys == fflib.DuplicateKeysAll {
+					if unknownKeys[string(kn)] {
+						return fs.WrapErr(&fflib.DuplicateKeyError{Key: string(kn)})
+					}
+					if unknownKeys == nil {
+						unknownKeys = make(map[string]bool)
+					}
+					unknownKeys[string(kn)] = true
+				}
+			}
+			state = fflib.FFParse_want_colon
+			goto mainparse
+
 		case fflib.FFParse_want_colon:
 			if tok != fflib.FFTok_colon {
 				wantedTok = fflib.FFTok_colon
@@ -822,7 +1198,24 @@ mainparse:
 {{range $index, $field := $si.Fields}}
 handle_{{$field.Name}}:
 	{{with $fieldName := $field.Name | printf "uj.%s"}}
-		{{handleField $ic $fieldName $field.JsonName $field.Typ $field.Pointer $field.ForceString}}
+	{
+		// 只解析 fs.Include 选中的字段，嵌套类型使用其子集
+		include := fs.Include
+		if include != nil {
+			sub, ok := include[{{$field.JsonName}}]
+			if !ok {
+				err = fs.SkipField(tok)
+				if err != nil {
+					return fs.WrapErr(err)
+				}
+				state = fflib.FFParse_after_value
+				goto mainparse
+			}
+			fs.Include = sub
+		}
+		{{handleStructField $ic $fieldName $field}}
+		fs.Include = include
+	}
 		{{if eq $.ResetFields true}}
 		ffj_set_{{$si.Name}}_{{$field.Name}} = true
 		{{end}}
@@ -898,6 +1291,8 @@ var handleUnmarshalerTxt = `
 
 	{{if eq .UnmarshalJSONFFLexer true}}
 	{
+		// No goto mainparse here: the field may be decoded inside an inline
+		// struct or a decode function, which have their own loop or no label.
 		if tok == fflib.FFTok_null {
 				{{if eq .Typ.Kind .Ptr }}
 					{{.Name}} = nil
@@ -905,9 +1300,7 @@ var handleUnmarshalerTxt = `
 				{{if eq .TakeAddr true }}
 					{{.Name}} = nil
 				{{end}}
-				state = fflib.FFParse_after_value
-				goto mainparse
-		}
+		} else {
 		{{if eq .Typ.Kind .Ptr }}
 			if {{.Name}} == nil {
 				{{.Name}} = new({{getType $ic .Typ.Elem.Name .Typ.Elem}})
@@ -921,27 +1314,32 @@ var handleUnmarshalerTxt = `
 		err = {{.Name}}.UnmarshalJSONFFLexer(fs, fflib.FFParse_want_key)
 		if err != nil {
 			// return err
+			if fflib.PassError(err) {
+				return fs.WrapErr(err)
+			}
 			return errors.New({{.JsonName}} + "格式错误")
 		}
-		state = fflib.FFParse_after_value
 
 		//handleUnmarshalerTxt
 		{{getSetFieldMarkFunc .Name}}
+		}
+		state = fflib.FFParse_after_value
 	}
 	{{else}}
 	{{if eq .Unmarshaler true}}
 	{
+		// No goto mainparse, as above.
 		if tok == fflib.FFTok_null {
 			{{if eq .TakeAddr true }}
 				{{.Name}} = nil
 			{{end}}
-			state = fflib.FFParse_after_value
-			goto mainparse
-		}
-
+		} else {
 		tbuf, err := fs.CaptureField(tok)
 		if err != nil {
 			// return fs.WrapErr(err)
+			if fflib.PassError(err) {
+				return fs.WrapErr(err)
+			}
 			return errors.New({{.JsonName}} + "格式错误")
 		}
 
@@ -955,11 +1353,48 @@ var handleUnmarshalerTxt = `
 			// return fs.WrapErr(err)
 			return errors.New({{.JsonName}} + "格式错误")
 		}
-		state = fflib.FFParse_after_value
 
 		//handleUnmarshalerTxt
 		{{getSetFieldMarkFunc .Name}}
+		}
+		state = fflib.FFParse_after_value
 	}
 	{{end}}
 	{{end}}
 `
+
+type decodeValue struct {
+	IC       *Inception
+	FuncName string
+	Field    *StructField
+}
+
+var decodeValueTxt = `
+{{$ic := .IC}}
+func {{.FuncName}}(fs *fflib.FFLexer, jsonName string, out *{{if eq .Field.Pointer true}}*{{end}}{{getTypeName $ic .Field.Typ}}) error {
+	var pv {{if eq .Field.Pointer true}}*{{end}}{{getTypeName $ic .Field.Typ}}
+	var err error
+	_ = err
+	state := fflib.FFParse_want_value
+	_ = state
+
+	tok := fs.Scan()
+	if tok == fflib.FFTok_error {
+		goto tokerror
+	}
+
+	{{handleStructField .IC "pv" .Field}}
+	*out = pv
+	return nil
+
+tokerror:
+	if fs.BigError != nil {
+		return fs.WrapErr(fs.BigError)
+	}
+	err = fs.Error.ToError()
+	if err != nil {
+		return fs.WrapErr(err)
+	}
+	panic("ffjson-generated: unreachable, please report bug.")
+}
+`
@@ -0,0 +1,113 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/yingshengtech/ffjson/shared"
+)
+
+// CreateDiff generates Diff, which compares two instances field by field
+// using the same per-field encoders as MarshalJSONBuf.
+func CreateDiff(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	out := ""
+	out += `//Diff 与 other 逐字段比较，返回 JSON 编码不同的字段（Path 为 RFC 6901 JSON Pointer），任一字段编码失败时返回该错误` + "\n"
+	out += `func (mj *` + si.Name + `) Diff(other *` + si.Name + `) ([]fflib.FieldChange, error) {` + "\n"
+	out += `if mj == nil {` + "\n"
+	out += `  mj = &` + si.Name + `{}` + "\n"
+	out += `}` + "\n"
+	out += `if other == nil {` + "\n"
+	out += `  other = &` + si.Name + `{}` + "\n"
+	out += `}` + "\n"
+	out += `var changes []fflib.FieldChange` + "\n"
+
+	compared := false
+	for _, f := range si.Fields {
+		path := strconv.Quote("/" + pointerToken(f))
+		encodeFunc := "ffj_encode_" + si.Name + "_" + f.Name
+
+		nested := typeInInception(ic, f.Typ, shared.MustEncoder) && f.Typ.Kind() != reflect.Ptr
+		if nested && !f.Pointer {
+			out += getNestedDiff(path, "&", f)
+			continue
+		}
+
+		createEncodeField(ic, si, f, encodeFunc)
+		if !compared {
+			ic.OutputImports[`"bytes"`] = true
+			out += `var a, b fflib.Buffer` + "\n"
+			compared = true
+		}
+		if nested {
+			out += `if mj.` + f.Name + ` != nil && other.` + f.Name + ` != nil {` + "\n"
+			out += getNestedDiff(path, "", f)
+			out += `} else {` + "\n"
+		} else {
+			out += `{` + "\n"
+		}
+		out += `a.Reset()` + "\n"
+		out += `b.Reset()` + "\n"
+		out += `if err := ` + encodeFunc + `(mj, &a); err != nil {` + "\n"
+		out += `  return nil, err` + "\n"
+		out += `}` + "\n"
+		out += `if err := ` + encodeFunc + `(other, &b); err != nil {` + "\n"
+		out += `  return nil, err` + "\n"
+		out += `}` + "\n"
+		out += `if !bytes.Equal(a.Bytes(), b.Bytes()) {` + "\n"
+		out += `  changes = append(changes, fflib.NewFieldChange(` + path + `, a.Bytes(), b.Bytes()))` + "\n"
+		out += `}` + "\n"
+		out += `}` + "\n"
+	}
+
+	out += `return changes, nil` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// getNestedDiff appends the changes of the nested generated type in f,
+// taking its address with ref.
+func getNestedDiff(path string, ref string, f *StructField) string {
+	out := ""
+	out += `if nested, err := mj.` + f.Name + `.Diff(` + ref + `other.` + f.Name + `); err != nil {` + "\n"
+	out += `  return nil, err` + "\n"
+	out += `} else {` + "\n"
+	out += `  changes = fflib.AppendFieldChanges(changes, ` + path + `, nested)` + "\n"
+	out += `}` + "\n"
+	return out
+}
+
+// createEncodeField adds a function that writes the value of a single field,
+// or nothing when the field is omitted.
+func createEncodeField(ic *Inception, si *StructInfo, f *StructField, funcName string) {
+	out := ""
+	out += `func ` + funcName + `(mj *` + si.Name + `, buf fflib.EncodingBuffer) error {` + "\n"
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+	out += getFieldValue(ic, f, "mj.")
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+}
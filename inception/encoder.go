@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"github.com/yingshengtech/ffjson/shared"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 func typeInInception(ic *Inception, typ reflect.Type, f shared.Feature) bool {
@@ -38,8 +40,8 @@ func typeInInception(ic *Inception, typ reflect.Type, f shared.Feature) bool {
 	return false
 }
 
-func getOmitEmpty(ic *Inception, sf *StructField) string {
-	ptname := "mj." + sf.Name
+func getOmitEmpty(ic *Inception, sf *StructField, prefix string) string {
+	ptname := prefix + sf.Name
 	if sf.Pointer {
 		ptname = "*" + ptname
 		return "if true {\n"
@@ -76,10 +78,127 @@ func getOmitEmpty(ic *Inception, sf *StructField) string {
 	}
 }
 
+// mapKeyKind is how the keys of a map are collected in a fflib.KeySlice to
+// be sorted, following the rules of encoding/json: string kinds are used
+// directly, then encoding.TextMarshaler, then integer kinds in base 10.
+type mapKeyKind int
+
+const (
+	mapKeyNone mapKeyKind = iota
+	mapKeyString
+	mapKeyText
+	mapKeyInt
+	mapKeyUint
+)
+
+func getMapKeyKind(typ reflect.Type) mapKeyKind {
+	if typ.Kind() == reflect.String {
+		return mapKeyString
+	}
+	if typ.Implements(textMarshalerType) {
+		return mapKeyText
+	}
+	switch typ.Kind() {
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64:
+		return mapKeyInt
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return mapKeyUint
+	}
+	return mapKeyNone
+}
+
+// getMapKeys returns the code collecting the keys of the map held in name
+// in ks, a pooled fflib.KeySlice, and sorting them. Keys of text kind are
+// also kept in keys, a slice of typ, in the order they were added.
+func getMapKeys(ic *Inception, name, ks, keys string, kind mapKeyKind, typ reflect.Type) string {
+	var out = ""
+
+	out += ks + " := fflib.GetKeySlice()" + "\n"
+	if kind == mapKeyText {
+		out += keys + " := make([]" + getTypeName(ic, typ) + ", 0, len(" + name + "))" + "\n"
+	}
+	out += "for key := range " + name + " {" + "\n"
+	switch kind {
+	case mapKeyString:
+		out += ks + ".Add(string(key))" + "\n"
+	case mapKeyText:
+		out += "kname := \"\"" + "\n"
+		if typ.Kind() == reflect.Ptr {
+			out += "if key != nil {" + "\n"
+		} else {
+			out += "{" + "\n"
+		}
+		out += "  kb, err := key.MarshalText()" + "\n"
+		out += "  if err != nil {" + "\n"
+		out += "    fflib.PutKeySlice(" + ks + ")" + "\n"
+		out += "    return err" + "\n"
+		out += "  }" + "\n"
+		out += "  kname = string(kb)" + "\n"
+		out += "}" + "\n"
+		out += ks + ".Add(kname)" + "\n"
+		out += keys + " = append(" + keys + ", key)" + "\n"
+	case mapKeyInt:
+		out += ks + ".AddInt(int64(key))" + "\n"
+	case mapKeyUint:
+		out += ks + ".AddUint(uint64(key))" + "\n"
+	}
+	out += "}" + "\n"
+	out += ks + ".Sort()" + "\n"
+	return out
+}
+
+// getMapKeyRange returns the code ranging over the sorted keys of ks,
+// declaring value, the map value of each key held in name, and writing the
+// key as a JSON string.
+func getMapKeyRange(ic *Inception, name, ks, keys string, kind mapKeyKind, typ reflect.Type) string {
+	var out = ""
+
+	keyType := getTypeName(ic, typ)
+	switch kind {
+	case mapKeyString:
+		out += "for _, kname := range " + ks + ".Names {" + "\n"
+		out += "  value := (" + name + ")[" + keyType + "(kname)]" + "\n"
+		out += "  fflib.WriteJsonString(buf, kname)" + "\n"
+	case mapKeyText:
+		out += "for i, kname := range " + ks + ".Names {" + "\n"
+		out += "  value := (" + name + ")[" + keys + "[" + ks + ".Perm[i]]]" + "\n"
+		out += "  fflib.WriteJsonString(buf, kname)" + "\n"
+	case mapKeyInt:
+		out += "for _, key := range " + ks + ".Ints {" + "\n"
+		out += "  value := (" + name + ")[" + keyType + "(key)]" + "\n"
+		out += "  buf.WriteByte('\"')" + "\n"
+		out += "  fflib.FormatBits2(buf, uint64(key), 10, key < 0)" + "\n"
+		out += "  buf.WriteByte('\"')" + "\n"
+	case mapKeyUint:
+		out += "for _, key := range " + ks + ".Uints {" + "\n"
+		out += "  value := (" + name + ")[" + keyType + "(key)]" + "\n"
+		out += "  buf.WriteByte('\"')" + "\n"
+		out += "  fflib.FormatBits2(buf, key, 10, false)" + "\n"
+		out += "  buf.WriteByte('\"')" + "\n"
+	}
+	return out
+}
+
+// putKeySliceOnReturn adds the release of ks before the error returns of
+// the generated code in out.
+func putKeySliceOnReturn(out, ks string) string {
+	return strings.Replace(out, "return err\n", "fflib.PutKeySlice("+ks+")\nreturn err\n", -1)
+}
+
 func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceString bool) string {
 	var out = ""
 
-	if typ.Key().Kind() != reflect.String {
+	keyKind := getMapKeyKind(typ.Key())
+	if keyKind == mapKeyNone {
 		out += fmt.Sprintf("/* Falling back. type=%v kind=%v */\n", typ, typ.Kind())
 		out += ic.q.Flush()
 		out += "err = buf.Encode(" + name + ")" + "\n"
@@ -91,6 +210,9 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 
 	var elemKind reflect.Kind
 	elemKind = typ.Elem().Kind()
+	if elemKind == reflect.Interface && typ.Elem().NumMethod() != 0 {
+		elemKind = reflect.Invalid
+	}
 
 	switch elemKind {
 	case reflect.String,
@@ -98,7 +220,8 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
 		reflect.Float32,
 		reflect.Float64,
-		reflect.Bool:
+		reflect.Bool,
+		reflect.Interface:
 
 		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
 
@@ -107,15 +230,18 @@ func getMapValue(ic *Inception, name string, typ reflect.Type, ptr bool, forceSt
 		out += ic.q.GetQueued()
 		ic.q.DeleteLast()
 		out += "} else {" + "\n"
+		// Keys are written sorted by their JSON name, like encoding/json.
+		// The pooled KeySlice is put back on every return, errors too.
+		out += getMapKeys(ic, name, "ks", "keys", keyKind, typ.Key())
 		out += ic.q.WriteFlush("{ ")
-		out += "  for key, value := range " + name + " {" + "\n"
-		out += "    fflib.WriteJsonString(buf, key)" + "\n"
+		out += getMapKeyRange(ic, name, "ks", "keys", keyKind, typ.Key())
 		out += "    buf.WriteString(`:`)" + "\n"
-		out += getGetInnerValue(ic, "value", typ.Elem(), false, forceString)
+		out += putKeySliceOnReturn(getGetInnerValue(ic, "value", typ.Elem(), false, forceString), "ks")
 		out += "    buf.WriteByte(',')" + "\n"
 		out += "  }" + "\n"
 		out += "buf.Rewind(1)" + "\n"
 		out += ic.q.WriteFlush("}")
+		out += "fflib.PutKeySlice(ks)" + "\n"
 		out += "}" + "\n"
 
 	default:
@@ -137,6 +263,11 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += ic.q.Flush()
 	}
 
+	if typ == timeType {
+		out += getTimeValue(ic, name, typ, ptr, timeFormat{})
+		return out
+	}
+
 	if typ.Implements(marshalerFasterType) ||
 		reflect.PtrTo(typ).Implements(marshalerFasterType) ||
 		typeInInception(ic, typ, shared.MustEncoder) ||
@@ -155,6 +286,17 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		return out
 	}
 
+	if typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType) {
+		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+		out += ic.q.Flush()
+		out += tplStr(encodeTpl["handleTextMarshaler"], handleTextMarshaler{
+			Name: name,
+			Typ:  typ,
+			Ptr:  reflect.Ptr,
+		})
+		return out
+	}
+
 	ptname := name
 	if ptr {
 		ptname = "*" + name
@@ -176,12 +318,13 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		reflect.Uintptr:
 		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
 		out += "fflib.FormatBits2(buf, uint64(" + ptname + "), 10, false)" + "\n"
-	case reflect.Float32:
-		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 32)" + "\n"
-	case reflect.Float64:
+	case reflect.Float32,
+		reflect.Float64:
 		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
-		out += "fflib.AppendFloat(buf, float64(" + ptname + "), 'g', -1, 64)" + "\n"
+		out += "err = fflib.WriteFloat(buf, float64(" + ptname + "), " + strconv.Itoa(typ.Bits()) + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
 	case reflect.Array,
 		reflect.Slice:
 
@@ -269,6 +412,15 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 		out += ic.q.WriteFlush("false")
 		out += "}" + "\n"
 	case reflect.Interface:
+		if typ.NumMethod() == 0 {
+			ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+			out += fmt.Sprintf("/* Generic values are written by fflib. type=%v kind=%v */\n", typ, typ.Kind())
+			out += "err = fflib.EncodeInterface(buf, " + ptname + ")" + "\n"
+			out += "if err != nil {" + "\n"
+			out += "  return err" + "\n"
+			out += "}" + "\n"
+			break
+		}
 		out += fmt.Sprintf("/* Interface types must use runtime reflection. type=%v kind=%v */\n", typ, typ.Kind())
 		out += "err = buf.Encode(" + name + ")" + "\n"
 		out += "if err != nil {" + "\n"
@@ -323,6 +475,25 @@ func getGetInnerValue(ic *Inception, name string, typ reflect.Type, ptr bool, fo
 }
 
 func getValue(ic *Inception, sf *StructField, prefix string) string {
+	if sf.Redact {
+		return getRedactedValue(ic, sf, prefix)
+	}
+
+	if useTimeFormat(sf.Typ, sf.TimeFormat) {
+		return getTimeValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.TimeFormat)
+	}
+
+	if sf.Variant {
+		ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+		out := ic.q.Flush()
+		out += fmt.Sprintf("/* Variant. type=%v key=%q */\n", sf.Typ, sf.VariantKey)
+		out += "err = fflib.WriteVariant(buf, &" + prefix + sf.Name + ", " + strconv.Quote(sf.VariantKey) + ")" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		return out
+	}
+
 	closequote := false
 	if sf.ForceString {
 		switch sf.Typ.Kind() {
@@ -344,7 +515,12 @@ func getValue(ic *Inception, sf *StructField, prefix string) string {
 			closequote = true
 		}
 	}
-	out := getGetInnerValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.ForceString)
+	var out string
+	if sf.HasPrecision {
+		out = getPrecisionValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.Precision)
+	} else {
+		out = getGetInnerValue(ic, prefix+sf.Name, sf.Typ, sf.Pointer, sf.ForceString)
+	}
 	if closequote {
 		if sf.Pointer {
 			out += ic.q.WriteFlush(`"`)
@@ -356,6 +532,48 @@ func getValue(ic *Inception, sf *StructField, prefix string) string {
 	return out
 }
 
+// getRedactedValue writes the field as usual, unless the buffer asks for
+// redaction: then as RedactedValue, or masked for the mask option.
+func getRedactedValue(ic *Inception, sf *StructField, prefix string) string {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	out := ic.q.Flush()
+	out += "if fflib.Redacting(buf) {" + "\n"
+	if sf.Mask {
+		name := prefix + sf.Name
+		if sf.Pointer {
+			name = "*" + name
+		}
+		out += "fflib.WriteMasked(buf, string(" + name + "), " + strconv.Itoa(sf.MaskHead) + ", " + strconv.Itoa(sf.MaskTail) + ")" + "\n"
+	} else {
+		out += "fflib.WriteJsonString(buf, fflib.RedactedValue)" + "\n"
+	}
+	out += "} else {" + "\n"
+
+	plain := *sf
+	plain.Redact = false
+	out += getValue(ic, &plain, prefix)
+	out += ic.q.Flush()
+	out += "}" + "\n"
+	return out
+}
+
+// getPrecisionValue writes the float held in name with prec digits after
+// the decimal point.
+func getPrecisionValue(ic *Inception, name string, typ reflect.Type, ptr bool, prec int) string {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	if ptr {
+		name = "*" + name
+	}
+	out := ic.q.Flush()
+	out += "err = fflib.WriteFixedFloat(buf, float64(" + name + "), " + strconv.Itoa(prec) + ", " + strconv.Itoa(typ.Bits()) + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
 func p2(v uint32) uint32 {
 	v--
 	v |= v >> 1
@@ -437,7 +655,7 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 		if f.Pointer {
 			out += "if " + prefix + f.Name + " != nil {" + "\n"
 		}
-		out += getOmitEmpty(ic, f)
+		out += getOmitEmpty(ic, f, prefix)
 	}
 
 	if f.Pointer && !f.OmitEmpty {
@@ -470,6 +688,40 @@ func getField(ic *Inception, f *StructField, prefix string) string {
 	return out
 }
 
+// getFieldValue is getField without the key: it writes only the value of f,
+// or nothing if the value is omitted by omitempty.
+func getFieldValue(ic *Inception, f *StructField, prefix string) string {
+	out := ""
+	if f.OmitEmpty {
+		out += ic.q.Flush()
+		if f.Pointer {
+			out += "if " + prefix + f.Name + " != nil {" + "\n"
+		}
+		out += getOmitEmpty(ic, f, prefix)
+	}
+
+	if f.Pointer && !f.OmitEmpty {
+		out += "if " + prefix + f.Name + " != nil {" + "\n"
+	}
+
+	out += getValue(ic, f, prefix)
+	out += ic.q.Flush()
+
+	if f.Pointer && !f.OmitEmpty {
+		out += "} else {" + "\n"
+		out += ic.q.WriteFlush("null")
+		out += "}" + "\n"
+	}
+
+	if f.OmitEmpty {
+		if f.Pointer {
+			out += "}" + "\n"
+		}
+		out += "}" + "\n"
+	}
+	return out
+}
+
 // We check if the last field is conditional.
 func lastConditional(fields []*StructField) bool {
 	if len(fields) > 0 {
@@ -498,6 +750,16 @@ func CreateMarshalJSON(ic *Inception, si *StructInfo) error {
 	out += `return buf.Bytes(), nil` + "\n"
 	out += `}` + "\n"
 
+	out += `func (mj *` + si.Name + `) MarshalJSONRedacted() ([]byte, error) {` + "\n"
+	out += `var buf fflib.Buffer` + "\n"
+	out += `buf.SetRedact(true)` + "\n"
+	out += `err := mj.MarshalJSONBuf(&buf)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += "  return nil, err" + "\n"
+	out += `}` + "\n"
+	out += `return buf.Bytes(), nil` + "\n"
+	out += `}` + "\n"
+
 	out += `func (mj *` + si.Name + `) MarshalJSONBuf(buf fflib.EncodingBuffer) (error) {` + "\n"
 	out += `  if mj == nil {` + "\n"
 	out += `    buf.WriteString("null")` + "\n"
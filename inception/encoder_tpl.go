@@ -28,7 +28,8 @@ func init() {
 	encodeTpl = make(map[string]*template.Template)
 
 	funcs := map[string]string{
-		"handleMarshaler": handleMarshalerTxt,
+		"handleMarshaler":     handleMarshalerTxt,
+		"handleTextMarshaler": handleTextMarshalerTxt,
 	}
 	tplFuncs := template.FuncMap{}
 
@@ -51,8 +52,9 @@ var handleMarshalerTxt = `
 		{{if eq .Typ.Kind .Ptr}}
 		if {{.Name}} == nil {
 			buf.WriteString("null")
-			return nil
-		}
+		} else {
+		{{else}}
+		{
 		{{end}}
 
 		{{if eq .MarshalJSONBuf true}}
@@ -67,5 +69,30 @@ var handleMarshalerTxt = `
 		}
 		buf.Write(obj)
 		{{end}}
+		}
+	}
+`
+
+type handleTextMarshaler struct {
+	Name string
+	Typ  reflect.Type
+	Ptr  reflect.Kind
+}
+
+var handleTextMarshalerTxt = `
+	{
+		{{if eq .Typ.Kind .Ptr}}
+		if {{.Name}} == nil {
+			buf.WriteString("null")
+		} else {
+		{{else}}
+		{
+		{{end}}
+			obj, err = {{.Name}}.MarshalText()
+			if err != nil {
+				return err
+			}
+			fflib.WriteJsonString(buf, string(obj))
+		}
 	}
 `
@@ -0,0 +1,203 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/yingshengtech/ffjson/shared"
+)
+
+// CreateMarshalJSONFields generates MarshalJSONFields, which writes only
+// the fields selected by a fflib.FieldSet. Every field costs one map lookup;
+// nested generated types are written with their own MarshalJSONFields, as
+// are the elements of slices, arrays and maps of them.
+func CreateMarshalJSONFields(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	out := ""
+	out += `//MarshalJSONFields 只序列化 include 中的字段，嵌套类型按子集序列化；include 为 nil 时序列化全部字段` + "\n"
+	out += `func (mj *` + si.Name + `) MarshalJSONFields(buf fflib.EncodingBuffer, include fflib.FieldSet) error {` + "\n"
+	out += `if include == nil {` + "\n"
+	out += `  return mj.MarshalJSONBuf(buf)` + "\n"
+	out += `}` + "\n"
+	out += `if mj == nil {` + "\n"
+	out += `  buf.WriteString("null")` + "\n"
+	out += `  return nil` + "\n"
+	out += `}` + "\n"
+	out += `var err error` + "\n"
+	out += `var obj []byte` + "\n"
+	out += `_ = obj` + "\n"
+	out += `_ = err` + "\n"
+
+	// Every field is conditional: each one writes its trailing comma, and
+	// the last one is taken back, or the space if nothing was written.
+	out += `buf.WriteString("{ ")` + "\n"
+	for _, f := range si.Fields {
+		nested := typeInInception(ic, f.Typ, shared.MustEncoder) && f.Typ.Kind() != reflect.Ptr
+		elems := !nested && hasFieldsElems(ic, f.Typ)
+		if nested {
+			out += `if sub, ok := include[` + f.JsonName + `]; ok {` + "\n"
+			out += `if sub != nil {` + "\n"
+			if f.Pointer && f.OmitEmpty {
+				out += `if mj.` + f.Name + ` != nil {` + "\n"
+			}
+			out += "buf.WriteString(`" + f.JsonName + ":`)" + "\n"
+			out += `err = mj.` + f.Name + `.MarshalJSONFields(buf, sub)` + "\n"
+			out += `if err != nil {` + "\n"
+			out += `  return err` + "\n"
+			out += `}` + "\n"
+			out += `buf.WriteByte(',')` + "\n"
+			if f.Pointer && f.OmitEmpty {
+				out += `}` + "\n"
+			}
+			out += `} else {` + "\n"
+		} else if elems {
+			out += `if sub, ok := include[` + f.JsonName + `]; ok {` + "\n"
+			out += `if sub != nil {` + "\n"
+			out += getFieldsElemsField(ic, f)
+			out += `} else {` + "\n"
+		} else {
+			out += `if _, ok := include[` + f.JsonName + `]; ok {` + "\n"
+		}
+		out += getField(ic, f, "mj.")
+		out += ic.q.Flush()
+		if nested || elems {
+			out += `}` + "\n"
+		}
+		out += `}` + "\n"
+	}
+	out += `buf.Rewind(1)` + "\n"
+	out += `buf.WriteByte('}')` + "\n"
+	out += `return nil` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// hasFieldsElems reports if typ is a slice, array or map reaching a generated
+// type, whose elements MarshalJSONFields writes with the nested set.
+func hasFieldsElems(ic *Inception, typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Map:
+		if getMapKeyKind(typ.Key()) == mapKeyNone {
+			return false
+		}
+	case reflect.Slice, reflect.Array:
+	default:
+		return false
+	}
+	elem := typ.Elem()
+	return typeInInception(ic, elem, shared.MustEncoder) || hasFieldsElems(ic, elem)
+}
+
+// getFieldsElemsField writes the field f, of a type for hasFieldsElems, with
+// the nested set sub applied to its elements.
+func getFieldsElemsField(ic *Inception, f *StructField) string {
+	out := ""
+	name := "mj." + f.Name
+	if f.OmitEmpty {
+		if f.Pointer {
+			out += "if " + name + " != nil {" + "\n"
+		}
+		out += getOmitEmpty(ic, f, "mj.")
+	}
+	if f.Pointer && !f.OmitEmpty {
+		out += "if " + name + " != nil {" + "\n"
+	}
+
+	out += "buf.WriteString(`" + f.JsonName + ":`)" + "\n"
+	if f.Pointer {
+		out += getFieldsElems(ic, "(*"+name+")", f.Typ, 0)
+	} else {
+		out += getFieldsElems(ic, name, f.Typ, 0)
+	}
+	out += "buf.WriteByte(',')" + "\n"
+
+	if f.Pointer && !f.OmitEmpty {
+		out += "} else {" + "\n"
+		out += "buf.WriteString(`" + f.JsonName + ":null,`)" + "\n"
+		out += "}" + "\n"
+	}
+	if f.OmitEmpty {
+		if f.Pointer {
+			out += "}" + "\n"
+		}
+		out += "}" + "\n"
+	}
+	return out
+}
+
+// getFieldsElems returns the code writing the value held in name, with sub
+// applied to the generated types it reaches. depth names the loop variables
+// of nested slices and arrays, and the key slices of nested maps.
+func getFieldsElems(ic *Inception, name string, typ reflect.Type, depth int) string {
+	out := ""
+	if typeInInception(ic, typ, shared.MustEncoder) {
+		out += "err = " + name + ".MarshalJSONFields(buf, sub)" + "\n"
+		out += "if err != nil {" + "\n"
+		out += "  return err" + "\n"
+		out += "}" + "\n"
+		return out
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		if typ.Kind() == reflect.Slice {
+			out += "if " + name + " == nil {" + "\n"
+			out += "  buf.WriteString(\"null\")" + "\n"
+			out += "} else {" + "\n"
+		}
+		i := fmt.Sprintf("i%d", depth)
+		out += "buf.WriteByte('[')" + "\n"
+		out += "for " + i + " := range " + name + " {" + "\n"
+		out += "if " + i + " != 0 {" + "\n"
+		out += "  buf.WriteByte(',')" + "\n"
+		out += "}" + "\n"
+		out += getFieldsElems(ic, name+"["+i+"]", typ.Elem(), depth+1)
+		out += "}" + "\n"
+		out += "buf.WriteByte(']')" + "\n"
+		if typ.Kind() == reflect.Slice {
+			out += "}" + "\n"
+		}
+
+	case reflect.Map:
+		// Keys are sorted as in getMapValue. Nested maps put back their
+		// own KeySlice, then each enclosing one, on an error return.
+		keyKind := getMapKeyKind(typ.Key())
+		ks := fmt.Sprintf("ks%d", depth)
+		keys := fmt.Sprintf("keys%d", depth)
+		out += "if " + name + " == nil {" + "\n"
+		out += "  buf.WriteString(\"null\")" + "\n"
+		out += "} else {" + "\n"
+		out += getMapKeys(ic, name, ks, keys, keyKind, typ.Key())
+		out += "buf.WriteString(\"{ \")" + "\n"
+		out += getMapKeyRange(ic, name, ks, keys, keyKind, typ.Key())
+		out += "buf.WriteString(`:`)" + "\n"
+		out += putKeySliceOnReturn(getFieldsElems(ic, "value", typ.Elem(), depth+1), ks)
+		out += "buf.WriteByte(',')" + "\n"
+		out += "}" + "\n"
+		out += "buf.Rewind(1)" + "\n"
+		out += "buf.WriteByte('}')" + "\n"
+		out += "fflib.PutKeySlice(" + ks + ")" + "\n"
+		out += "}" + "\n"
+	}
+	return out
+}
@@ -37,6 +37,7 @@ type Inception struct {
 	OutputFuncs   []string
 	q             ConditionalWrite
 	ResetFields   bool
+	decodeFuncs   map[decodeFuncKey]string
 }
 
 func NewInception(inputPath string, packageName string, outputPath string, resetFields bool) *Inception {
@@ -48,6 +49,7 @@ func NewInception(inputPath string, packageName string, outputPath string, reset
 		OutputFuncs:   make([]string, 0),
 		OutputImports: make(map[string]bool),
 		ResetFields:   resetFields,
+		decodeFuncs:   make(map[decodeFuncKey]string),
 	}
 }
 
@@ -108,6 +110,16 @@ func (i *Inception) generateCode() error {
 			if err != nil {
 				return err
 			}
+
+			err = CreateDiff(i, si)
+			if err != nil {
+				return err
+			}
+
+			err = CreateMarshalJSONFields(i, si)
+			if err != nil {
+				return err
+			}
 		}
 
 		if i.wantUnmarshal(si) {
@@ -115,6 +127,11 @@ func (i *Inception) generateCode() error {
 			if err != nil {
 				return err
 			}
+
+			err = CreatePointer(i, si)
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,387 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/yingshengtech/ffjson/shared"
+)
+
+// CreatePointer generates GetPointer and SetPointer, which resolve RFC 6901
+// JSON Pointers against the ffj_key_* tables of the decoder.
+func CreatePointer(ic *Inception, si *StructInfo) error {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	out := ""
+	out += `//GetPointer 按 RFC 6901 JSON Pointer（如 /address/city、/items/3/price）读取值，interface{} 字段的值不再向下解析` + "\n"
+	out += `func (uj *` + si.Name + `) GetPointer(ptr string) (interface{}, error) {` + "\n"
+	out += `if ptr == "" {` + "\n"
+	out += `  return uj, nil` + "\n"
+	out += `}` + "\n"
+	out += `tok, rest, err := fflib.SplitPointer(ptr)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += `  return nil, err` + "\n"
+	out += `}` + "\n"
+	out += `switch tok {` + "\n"
+	for _, f := range si.Fields {
+		out += `case string(ffj_key_` + si.Name + `_` + f.Name + `):` + "\n"
+		out += getPointerGet(ic, "uj."+f.Name, getPointerFieldType(f), "rest", 1)
+	}
+	out += `}` + "\n"
+	out += `return nil, fflib.ErrPointerNotFound` + "\n"
+	out += `}` + "\n"
+
+	out += `//SetPointer 按 RFC 6901 JSON Pointer 将 raw（JSON 编码）写入对应的值，并设置赋值标识；interface{} 字段的值只能整体写入` + "\n"
+	out += `func (uj *` + si.Name + `) SetPointer(ptr string, raw []byte) error {` + "\n"
+	out += `fs := fflib.NewFFLexer(raw)` + "\n"
+	out += `if ptr == "" {` + "\n"
+	out += `  return uj.UnmarshalJSONFFLexer(fs, fflib.FFParse_map_start)` + "\n"
+	out += `}` + "\n"
+	out += `tok, rest, err := fflib.SplitPointer(ptr)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += `  return err` + "\n"
+	out += `}` + "\n"
+	out += `switch tok {` + "\n"
+	for _, f := range si.Fields {
+		ps := &pointerSetter{
+			IC:       ic,
+			SI:       si,
+			JsonName: f.JsonName,
+		}
+		done := `uj.SetFieldMark("` + f.Name + `")` + "\n" + `return nil` + "\n"
+
+		out += `case string(ffj_key_` + si.Name + `_` + f.Name + `):` + "\n"
+		out += ps.leaf("uj."+f.Name, f, "rest", done)
+		out += ps.descend("uj."+f.Name, getPointerFieldType(f), "rest", 1, done)
+	}
+	out += `}` + "\n"
+	out += `return fflib.ErrPointerNotFound` + "\n"
+	out += `}` + "\n"
+
+	ic.OutputFuncs = append(ic.OutputFuncs, out)
+	return nil
+}
+
+// pointerToken returns the escaped JSON Pointer reference token of f.
+func pointerToken(f *StructField) string {
+	name, err := strconv.Unquote(f.JsonName)
+	if err != nil {
+		name = f.Name
+	}
+	name = strings.Replace(name, "~", "~0", -1)
+	return strings.Replace(name, "/", "~1", -1)
+}
+
+// getPointerFieldType returns the Go type of the field, including the
+// pointer that extractFields strips from unnamed pointer types.
+func getPointerFieldType(f *StructField) reflect.Type {
+	if f.Pointer && f.Typ.Kind() != reflect.Ptr {
+		return reflect.PtrTo(f.Typ)
+	}
+	return f.Typ
+}
+
+// hasPointerMethods reports if typ gets (or has) its own GetPointer/SetPointer.
+func hasPointerMethods(ic *Inception, typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		return false
+	}
+	return typeInInception(ic, typ, shared.MustDecoder) ||
+		(reflect.PtrTo(typ).Implements(pointerGetterType) && reflect.PtrTo(typ).Implements(pointerSetterType))
+}
+
+// isPointerContainer reports if the pointer may descend into values of typ.
+// Types with their own UnmarshalJSON are always treated as leaf values, as
+// are interface{} values.
+func isPointerContainer(ic *Inception, typ reflect.Type) bool {
+	if typ.Implements(unmarshalerType) || reflect.PtrTo(typ).Implements(unmarshalerType) ||
+		reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Slice:
+		return typ.Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return true
+	case reflect.Map:
+		return isPointerMapKey(typ.Key())
+	case reflect.Struct:
+		// Inline struct, decoded natively.
+		return typ.Name() == ""
+	}
+	return false
+}
+
+// isPointerMapKey reports if reference tokens convert to map keys of typ,
+// with the rules of handleMapKey.
+func isPointerMapKey(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String,
+		reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+// getPointerKey declares key, the map key of typ read from the reference
+// token tok. Tokens that are not a valid key run notFound.
+func getPointerKey(ic *Inception, key, tok string, typ reflect.Type, notFound string) string {
+	out := ""
+	if typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		out += `var ` + key + ` ` + getTypeName(ic, typ) + "\n"
+		out += `if err := ` + key + `.UnmarshalText([]byte(` + tok + `)); err != nil {` + "\n"
+		out += `  ` + notFound
+		out += `}` + "\n"
+		return out
+	}
+
+	parse := ""
+	switch typ.Kind() {
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64:
+		parse = "ParseInt"
+	case reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr:
+		parse = "ParseUint"
+	default:
+		return key + ` := ` + getTypeName(ic, typ) + `(` + tok + `)` + "\n"
+	}
+
+	out += key + `n, err := fflib.` + parse + `([]byte(` + tok + `), 10, ` + getNumberSize(typ) + `)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += `  ` + notFound
+	out += `}` + "\n"
+	out += key + ` := ` + getTypeName(ic, typ) + `(` + key + `n)` + "\n"
+	return out
+}
+
+// getPointerFields returns the fields of the inline struct typ.
+func getPointerFields(typ reflect.Type) []*StructField {
+	return extractFields(reflect.Indirect(reflect.New(typ)).Interface())
+}
+
+func getPointerGet(ic *Inception, name string, typ reflect.Type, rest string, depth int) string {
+	out := ""
+	out += `if ` + rest + ` == "" {` + "\n"
+	out += `  return ` + name + `, nil` + "\n"
+	out += `}` + "\n"
+
+	for typ.Kind() == reflect.Ptr && !hasPointerMethods(ic, typ.Elem()) {
+		out += `if ` + name + ` == nil {` + "\n"
+		out += `  return nil, fflib.ErrPointerNotFound` + "\n"
+		out += `}` + "\n"
+		name = "(*" + name + ")"
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() == reflect.Ptr {
+		out += `if ` + name + ` == nil {` + "\n"
+		out += `  return nil, fflib.ErrPointerNotFound` + "\n"
+		out += `}` + "\n"
+		out += `return ` + name + `.GetPointer(` + rest + `)` + "\n"
+		return out
+	}
+
+	if hasPointerMethods(ic, typ) {
+		out += `return ` + name + `.GetPointer(` + rest + `)` + "\n"
+		return out
+	}
+
+	if !isPointerContainer(ic, typ) {
+		out += `return nil, fflib.ErrPointerNotFound` + "\n"
+		return out
+	}
+
+	tok := fmt.Sprintf("tok%d", depth)
+	next := fmt.Sprintf("rest%d", depth)
+	out += tok + `, ` + next + `, err := fflib.SplitPointer(` + rest + `)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += `  return nil, err` + "\n"
+	out += `}` + "\n"
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		idx := fmt.Sprintf("idx%d", depth)
+		out += idx + `, err := fflib.PointerIndex(` + tok + `, len(` + name + `))` + "\n"
+		out += `if err != nil || ` + idx + ` >= len(` + name + `) {` + "\n"
+		out += `  return nil, fflib.ErrPointerNotFound` + "\n"
+		out += `}` + "\n"
+		out += getPointerGet(ic, name+"["+idx+"]", typ.Elem(), next, depth+1)
+	case reflect.Map:
+		key := fmt.Sprintf("key%d", depth)
+		mv := fmt.Sprintf("mv%d", depth)
+		out += getPointerKey(ic, key, tok, typ.Key(), `return nil, fflib.ErrPointerNotFound`+"\n")
+		out += mv + `, ok := ` + name + `[` + key + `]` + "\n"
+		out += `if !ok {` + "\n"
+		out += `  return nil, fflib.ErrPointerNotFound` + "\n"
+		out += `}` + "\n"
+		out += getPointerGet(ic, mv, typ.Elem(), next, depth+1)
+	case reflect.Struct:
+		out += `switch ` + tok + ` {` + "\n"
+		for _, f := range getPointerFields(typ) {
+			out += `case ` + f.JsonName + `:` + "\n"
+			out += getPointerGet(ic, name+"."+f.Name, getPointerFieldType(f), next, depth+1)
+		}
+		out += `}` + "\n"
+		out += `return nil, fflib.ErrPointerNotFound` + "\n"
+	}
+	return out
+}
+
+type pointerSetter struct {
+	IC       *Inception
+	SI       *StructInfo
+	JsonName string
+}
+
+// leaf writes the value of raw to name, the field f, if the pointer ends at
+// rest. Errors of the decoder get the position in raw.
+func (ps *pointerSetter) leaf(name string, f *StructField, rest string, done string) string {
+	out := ""
+	out += `if ` + rest + ` == "" {` + "\n"
+	out += `  if err := ` + getDecodeFunc(ps.IC, ps.SI, f) + `(fs, ` + ps.JsonName + `, &` + name + `); err != nil {` + "\n"
+	out += `    return fs.WrapErr(err)` + "\n"
+	out += `  }` + "\n"
+	out += done
+	out += `}` + "\n"
+	return out
+}
+
+// descend resolves the rest of the pointer below name, which is known to be
+// non-empty, running done after the value has been written.
+func (ps *pointerSetter) descend(name string, typ reflect.Type, rest string, depth int, done string) string {
+	ic := ps.IC
+	out := ""
+
+	for typ.Kind() == reflect.Ptr {
+		out += `if ` + name + ` == nil {` + "\n"
+		out += `  ` + name + ` = new(` + getTypeName(ic, typ.Elem()) + `)` + "\n"
+		out += `}` + "\n"
+		typ = typ.Elem()
+		if hasPointerMethods(ic, typ) {
+			break
+		}
+		name = "(*" + name + ")"
+	}
+
+	if hasPointerMethods(ic, typ) {
+		out += `if err := ` + name + `.SetPointer(` + rest + `, raw); err != nil {` + "\n"
+		out += `  return err` + "\n"
+		out += `}` + "\n"
+		out += done
+		return out
+	}
+
+	if !isPointerContainer(ic, typ) {
+		out += `return fflib.ErrPointerNotFound` + "\n"
+		return out
+	}
+
+	tok := fmt.Sprintf("tok%d", depth)
+	next := fmt.Sprintf("rest%d", depth)
+	out += tok + `, ` + next + `, err := fflib.SplitPointer(` + rest + `)` + "\n"
+	out += `if err != nil {` + "\n"
+	out += `  return err` + "\n"
+	out += `}` + "\n"
+
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		elemField := &StructField{Typ: typ.Elem()}
+		idx := fmt.Sprintf("idx%d", depth)
+		out += idx + `, err := fflib.PointerIndex(` + tok + `, len(` + name + `))` + "\n"
+		out += `if err != nil {` + "\n"
+		out += `  return err` + "\n"
+		out += `}` + "\n"
+		if typ.Kind() == reflect.Slice {
+			// "-" appends a new element to the slice.
+			av := fmt.Sprintf("av%d", depth)
+			out += `if ` + idx + ` == len(` + name + `) {` + "\n"
+			out += `  if ` + next + ` != "" {` + "\n"
+			out += `    return fflib.ErrPointerNotFound` + "\n"
+			out += `  }` + "\n"
+			out += `  var ` + av + ` ` + getTypeName(ic, typ.Elem()) + "\n"
+			out += `  if err := ` + getDecodeFunc(ic, ps.SI, elemField) + `(fs, ` + ps.JsonName + `, &` + av + `); err != nil {` + "\n"
+			out += `    return fs.WrapErr(err)` + "\n"
+			out += `  }` + "\n"
+			out += `  ` + name + ` = append(` + name + `, ` + av + `)` + "\n"
+			out += done
+			out += `}` + "\n"
+		} else {
+			out += `if ` + idx + ` >= len(` + name + `) {` + "\n"
+			out += `  return fflib.ErrPointerNotFound` + "\n"
+			out += `}` + "\n"
+		}
+		elem := name + "[" + idx + "]"
+		out += ps.leaf(elem, elemField, next, done)
+		out += ps.descend(elem, typ.Elem(), next, depth+1, done)
+	case reflect.Map:
+		key := fmt.Sprintf("key%d", depth)
+		mv := fmt.Sprintf("mv%d", depth)
+		elemField := &StructField{Typ: typ.Elem()}
+		done = name + `[` + key + `] = ` + mv + "\n" + done
+		out += getPointerKey(ic, key, tok, typ.Key(), `return fflib.ErrPointerNotFound`+"\n")
+		out += `if ` + name + ` == nil {` + "\n"
+		out += `  ` + name + ` = make(` + getTypeName(ic, typ) + `)` + "\n"
+		out += `}` + "\n"
+		out += `var ` + mv + ` ` + getTypeName(ic, typ.Elem()) + "\n"
+		out += ps.leaf(mv, elemField, next, done)
+		// mv is declared above, so every depth needs its own ok.
+		ok := fmt.Sprintf("ok%d", depth)
+		out += mv + `, ` + ok + ` := ` + name + `[` + key + `]` + "\n"
+		out += `if !` + ok + ` {` + "\n"
+		out += `  return fflib.ErrPointerNotFound` + "\n"
+		out += `}` + "\n"
+		out += ps.descend(mv, typ.Elem(), next, depth+1, done)
+	case reflect.Struct:
+		out += `switch ` + tok + ` {` + "\n"
+		for _, f := range getPointerFields(typ) {
+			out += `case ` + f.JsonName + `:` + "\n"
+			out += ps.leaf(name+"."+f.Name, f, next, done)
+			out += ps.descend(name+"."+f.Name, getPointerFieldType(f), next, depth+1, done)
+		}
+		out += `}` + "\n"
+		out += `return fflib.ErrPointerNotFound` + "\n"
+	}
+	return out
+}
@@ -24,9 +24,11 @@ import (
 	"github.com/yingshengtech/ffjson/shared"
 
 	"bytes"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"unicode/utf8"
 )
 
@@ -41,6 +43,15 @@ type StructField struct {
 	HasUnmarshalJSON bool
 	Pointer          bool
 	Tagged           bool
+	TimeFormat       timeFormat
+	Variant          bool
+	VariantKey       string
+	HasPrecision     bool
+	Precision        int
+	Redact           bool
+	Mask             bool
+	MaskHead         int
+	MaskTail         int
 }
 
 type FieldByJsonName []*StructField
@@ -138,10 +149,22 @@ type UnmarshalFaster interface {
 	UnmarshalJSONFFLexer(l *fflib.FFLexer, state fflib.FFParseState) error
 }
 
+type PointerGetter interface {
+	GetPointer(ptr string) (interface{}, error)
+}
+
+type PointerSetter interface {
+	SetPointer(ptr string, raw []byte) error
+}
+
 var marshalerType = reflect.TypeOf(new(json.Marshaler)).Elem()
 var marshalerFasterType = reflect.TypeOf(new(MarshalerFaster)).Elem()
 var unmarshalerType = reflect.TypeOf(new(json.Unmarshaler)).Elem()
 var unmarshalFasterType = reflect.TypeOf(new(UnmarshalFaster)).Elem()
+var textMarshalerType = reflect.TypeOf(new(encoding.TextMarshaler)).Elem()
+var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
+var pointerGetterType = reflect.TypeOf(new(PointerGetter)).Elem()
+var pointerSetterType = reflect.TypeOf(new(PointerSetter)).Elem()
 
 // extractFields returns a list of fields that JSON should recognize for the given type.
 // The algorithm is breadth-first search over the set of structs to include - the top struct
@@ -221,6 +244,36 @@ func extractFields(obj interface{}) []*StructField {
 						Tagged:           tagged,
 					}
 
+					_, ffopts := parseTag(sf.Tag.Get("ffjson"))
+					field.TimeFormat = parseTimeFormat(ffopts)
+					if field.TimeFormat.isSet() && !isTimeType(ft) {
+						panic("ffjson: time options on a field that is not a time: " + sf.Name)
+					}
+					field.VariantKey, field.Variant = ffopts.Value("variant")
+					if field.Variant && (ptr || ft.Kind() != reflect.Interface) {
+						panic("ffjson: variant option on a field that is not an interface: " + sf.Name)
+					}
+					if prec, ok := ffopts.Value("precision"); ok {
+						n, err := strconv.Atoi(prec)
+						if err != nil || n < 0 {
+							panic("ffjson: invalid precision on field " + sf.Name + ": " + prec)
+						}
+						if ft.Kind() != reflect.Float32 && ft.Kind() != reflect.Float64 {
+							panic("ffjson: precision option on a field that is not a float: " + sf.Name)
+						}
+						field.HasPrecision = true
+						field.Precision = n
+					}
+					field.Redact = ffopts.Contains("redact")
+					if mask, ok := ffopts.Tail("mask"); ok {
+						if ft.Kind() != reflect.String {
+							panic("ffjson: mask option on a field that is not a string: " + sf.Name)
+						}
+						field.Redact = true
+						field.Mask = true
+						field.MaskHead, field.MaskTail = parseMask(sf.Name, mask)
+					}
+
 					fields = append(fields, field)
 
 					if count[f.Typ] > 1 {
@@ -18,6 +18,7 @@
 package ffjsoninception
 
 import (
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -59,6 +60,57 @@ func (o tagOptions) Contains(optionName string) bool {
 	return false
 }
 
+// Value returns the value of the "optionName=value" option, or whether
+// the option is present without a value.
+func (o tagOptions) Value(optionName string) (string, bool) {
+	if o.Contains(optionName) {
+		return "", true
+	}
+	s := string(o)
+	for s != "" {
+		var next string
+		i := strings.Index(s, ",")
+		if i >= 0 {
+			s, next = s[:i], s[i+1:]
+		}
+		if strings.HasPrefix(s, optionName+"=") {
+			return s[len(optionName)+1:], true
+		}
+		s = next
+	}
+	return "", false
+}
+
+// Tail returns the value of the "optionName=value" option. The value
+// extends to the end of the options, so it may contain commas; such an
+// option must come last.
+func (o tagOptions) Tail(optionName string) (string, bool) {
+	s := "," + string(o)
+	i := strings.Index(s, ","+optionName+"=")
+	if i < 0 {
+		return "", false
+	}
+	return s[i+len(optionName)+2:], true
+}
+
+// parseMask reads the value of the "mask=head,tail" option, the number of
+// characters kept at the start and the end of a string. The tail may be
+// left out, and is followed by the other options.
+func parseMask(field string, mask string) (int, int) {
+	parts := strings.SplitN(mask, ",", 3)
+	head, err := strconv.Atoi(parts[0])
+	if err != nil || head < 0 {
+		panic("ffjson: invalid mask on field " + field + ": " + mask)
+	}
+	tail := 0
+	if len(parts) > 1 {
+		if n, err := strconv.Atoi(parts[1]); err == nil && n >= 0 {
+			tail = n
+		}
+	}
+	return head, tail
+}
+
 func isValidTag(s string) bool {
 	if s == "" {
 		return false
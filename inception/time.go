@@ -0,0 +1,144 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ffjsoninception
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeFormat is the wire format of a time field, set with the ffjson tag
+// options "layout=...", "unix" and "unixmilli". The zero value is the
+// format of time.Time's MarshalJSON.
+type timeFormat struct {
+	Layout string
+	Unix   bool
+	Milli  bool
+}
+
+func (tf timeFormat) isSet() bool {
+	return tf.Layout != "" || tf.Unix
+}
+
+// parseTimeFormat reads the time options of a ffjson tag. The layout takes
+// the rest of the tag, so it may contain commas.
+func parseTimeFormat(opts tagOptions) timeFormat {
+	var tf timeFormat
+	if layout, ok := opts.Tail("layout"); ok {
+		if strings.IndexFunc(layout, func(r rune) bool { return r < 0x20 || r == '"' || r == '\\' }) >= 0 {
+			panic("ffjson: time layout must not contain quotes, backslashes or control characters: " + layout)
+		}
+		tf.Layout = layout
+	}
+	if opts.Contains("unix") {
+		tf.Unix = true
+	}
+	if opts.Contains("unixmilli") {
+		tf.Unix = true
+		tf.Milli = true
+	}
+	return tf
+}
+
+// isTimeType reports if values of typ convert to time.Time: time.Time
+// itself, types defined on it (type Datetime time.Time) and structs
+// embedding it.
+func isTimeType(typ reflect.Type) bool {
+	return typ == timeType || typ.ConvertibleTo(timeType) || isTimeEmbedded(typ)
+}
+
+func isTimeEmbedded(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	sf, ok := typ.FieldByName("Time")
+	return ok && sf.Anonymous && len(sf.Index) == 1 && sf.Type == timeType
+}
+
+// useTimeFormat reports if a value of typ is written natively with tf.
+// Types other than time.Time keep their own methods unless a format is set.
+func useTimeFormat(typ reflect.Type, tf timeFormat) bool {
+	if typ == timeType {
+		return true
+	}
+	return tf.isSet() && isTimeType(typ)
+}
+
+// getTimeExpr returns name, a typ (or *typ if ptr is set), as time.Time.
+func getTimeExpr(ic *Inception, name string, typ reflect.Type, ptr bool) string {
+	if ptr {
+		name = "(*" + name + ")"
+	}
+	switch {
+	case typ == timeType:
+		return name
+	case isTimeEmbedded(typ):
+		return name + ".Time"
+	}
+	ic.OutputImports[`"time"`] = true
+	return "time.Time(" + name + ")"
+}
+
+// getTimeAssign returns the statement storing val, a time.Time, in name.
+func getTimeAssign(ic *Inception, name string, typ reflect.Type, takeAddr bool, val string) string {
+	if isTimeEmbedded(typ) {
+		return name + ".Time = " + val
+	}
+	if takeAddr {
+		name = "*" + name
+	}
+	if typ == timeType {
+		return name + " = " + val
+	}
+	return name + " = " + getTypeName(ic, typ) + "(" + val + ")"
+}
+
+// getTimeValue writes the time held in name with tf.
+func getTimeValue(ic *Inception, name string, typ reflect.Type, ptr bool, tf timeFormat) string {
+	ic.OutputImports[`fflib "github.com/yingshengtech/ffjson/fflib/v1"`] = true
+
+	out := ic.q.Flush()
+	expr := getTimeExpr(ic, name, typ, ptr)
+	if tf.Unix {
+		out += "fflib.WriteUnixTime(buf, " + expr + ", " + strconv.FormatBool(tf.Milli) + ")" + "\n"
+		return out
+	}
+	out += "err = fflib.WriteTime(buf, " + expr + ", " + strconv.Quote(tf.Layout) + ")" + "\n"
+	out += "if err != nil {" + "\n"
+	out += "  return err" + "\n"
+	out += "}" + "\n"
+	return out
+}
+
+// getTimeHandler decodes a time into name with tf.
+func getTimeHandler(ic *Inception, name, jsonName string, typ reflect.Type, takeAddr bool, tf timeFormat) string {
+	autoImport(ic, typ)
+	return tplStr(decodeTpl["handleTime"], handleTime{
+		IC:       ic,
+		Name:     name,
+		JsonName: jsonName,
+		Typ:      typ,
+		TakeAddr: takeAddr,
+		Format:   tf,
+		Assign:   getTimeAssign(ic, name, typ, takeAddr, "tval"),
+	})
+}
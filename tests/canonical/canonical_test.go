@@ -0,0 +1,51 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package canonical
+
+import (
+	"testing"
+
+	"github.com/yingshengtech/ffjson/ffjson"
+	ff "github.com/yingshengtech/ffjson/tests/canonical/ff"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	p := ff.NewPayload()
+	p.Zeta = "< \x01\"é"
+	p.Alpha = 1e21
+	p.Small = 0.1
+	p.Big = 1 << 40
+	// U+1F600 is written as a surrogate pair, and sorts before U+E000.
+	p.Keys = map[string]int{"\ue000": 1, "\U0001F600": 2, "b": 3, "B": 4}
+	p.Extra = map[string]interface{}{"n": -0.0, "m": 1e-7, "l": []interface{}{333333333.33333329, 4.5}}
+	p.Inner = ff.NewPayload()
+	p.Text = "x"
+
+	b, err := ffjson.MarshalCanonical(p)
+	if err != nil {
+		t.Fatalf("MarshalCanonical: %v", err)
+	}
+	expected := `{"alpha":1e+21,"big":1099511627776,` +
+		`"extra":{"l":[333333333.3333333,4.5],"m":1e-7,"n":0},` +
+		`"inner":{"alpha":0,"big":0,"extra":null,"keys":null,"small":0,"zeta":"","é":""},` +
+		`"keys":{"B":4,"b":3,"` + "\U0001F600" + `":2,"` + "\ue000" + `":1},` +
+		`"small":0.1,"zeta":"<` + " " + `\u0001\"é","é":"x"}`
+	if string(b) != expected {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+	}
+}
@@ -0,0 +1,31 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+// Payload lists its fields out of the canonical key order.
+type Payload struct {
+	Zeta      string                 `json:"zeta"`
+	Alpha     float64                `json:"alpha"`
+	Small     float32                `json:"small"`
+	Big       int64                  `json:"big"`
+	Keys      map[string]int         `json:"keys"`
+	Extra     map[string]interface{} `json:"extra"`
+	Inner     *Payload               `json:"inner,omitempty"`
+	Text      string                 `json:"é"`
+	fieldMark map[string]bool        `xorm:"-"`
+}
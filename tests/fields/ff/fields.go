@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+type Person struct {
+	Name      string          `json:"name"`
+	Email     string          `json:"email"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+type Item struct {
+	SKU       string          `json:"sku"`
+	Qty       int             `json:"qty"`
+	Price     float64         `json:"price"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+type Order struct {
+	ID        int64                      `json:"id"`
+	Status    string                     `json:"status"`
+	Owner     Person                     `json:"owner"`
+	Buyer     *Person                    `json:"buyer"`
+	Items     []Item                     `json:"items"`
+	Refs      []*Item                    `json:"refs"`
+	Grid      [2][]Item                  `json:"grid"`
+	ByKey     map[string]Item            `json:"by_key"`
+	ByID      map[int64]map[string]*Item `json:"by_id"`
+	Opt       *[]Item                    `json:"opt"`
+	Extra     []Item                     `json:"extra,omitempty"`
+	fieldMark map[string]bool            `xorm:"-"`
+}
+
+type Shipment struct {
+	ID      int64 `json:"id"`
+	Address struct {
+		City   string `json:"city"`
+		Street string `json:"street"`
+		Geo    *struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		} `json:"geo"`
+	} `json:"address"`
+	fieldMark map[string]bool `xorm:"-"`
+}
@@ -0,0 +1,186 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/fields/ff"
+)
+
+// plainOrder has no methods, so encoding/json writes it by reflection.
+type plainOrder ff.Order
+
+func newOrder() *ff.Order {
+	item := func(sku string) ff.Item {
+		i := ff.NewItem()
+		i.SKU, i.Qty, i.Price = sku, 2, 1.5
+		return *i
+	}
+	a, b := item("a"), item("b")
+	o := ff.NewOrder()
+	o.ID = 7
+	o.Status = "paid"
+	o.Owner = ff.Person{Name: "o", Email: "o@x"}
+	o.Buyer = &ff.Person{Name: "b", Email: "b@x"}
+	o.Items = []ff.Item{a, b}
+	o.Refs = []*ff.Item{&a, nil}
+	o.Grid = [2][]ff.Item{{a}, nil}
+	o.ByKey = map[string]ff.Item{"y": b, "x": a}
+	o.ByID = map[int64]map[string]*ff.Item{10: {"k": &b}, 9: nil}
+	o.Opt = &[]ff.Item{b}
+	return o
+}
+
+func compact(t *testing.T, b []byte) string {
+	var out bytes.Buffer
+	if err := json.Compact(&out, b); err != nil {
+		t.Fatalf("Compact(%s): %v", b, err)
+	}
+	return out.String()
+}
+
+func TestMarshalJSONFields(t *testing.T) {
+	tests := []struct {
+		include  fflib.FieldSet
+		expected string
+	}{
+		{fflib.NewFieldSet("id", "owner.name"), `{"id":7,"owner":{"name":"o"}}`},
+		{fflib.NewFieldSet("items.sku", "refs.qty"),
+			`{"items":[{"sku":"a"},{"sku":"b"}],"refs":[{"qty":2},null]}`},
+		{fflib.NewFieldSet("grid.sku", "by_key.sku", "by_id.price", "opt.sku", "extra.sku"),
+			`{"grid":[[{"sku":"a"}],null],"by_key":{"x":{"sku":"a"},"y":{"sku":"b"}},` +
+				`"by_id":{"10":{"k":{"price":1.5}},"9":null},"opt":[{"sku":"b"}]}`},
+		{fflib.NewFieldSet("items", "buyer.email"),
+			`{"buyer":{"email":"b@x"},"items":[{"sku":"a","qty":2,"price":1.5},{"sku":"b","qty":2,"price":1.5}]}`},
+		{fflib.NewFieldSet(), `{}`},
+	}
+
+	o := newOrder()
+	for _, test := range tests {
+		var buf fflib.Buffer
+		if err := o.MarshalJSONFields(&buf, test.include); err != nil {
+			t.Fatalf("MarshalJSONFields(%v): %v", test.include, err)
+		}
+		if compact(t, buf.Bytes()) != test.expected {
+			t.Fatalf("Expected: %s\nGot:      %s", test.expected, buf.Bytes())
+		}
+	}
+
+	// A nil set writes every field, like encoding/json.
+	var buf fflib.Buffer
+	if err := o.MarshalJSONFields(&buf, nil); err != nil {
+		t.Fatalf("MarshalJSONFields: %v", err)
+	}
+	expected, err := json.Marshal((*plainOrder)(o))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if compact(t, buf.Bytes()) != string(expected) {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, buf.Bytes())
+	}
+
+	o.Opt = nil
+	o.Items = nil
+	buf.Reset()
+	if err := o.MarshalJSONFields(&buf, fflib.NewFieldSet("items.sku", "opt.sku")); err != nil {
+		t.Fatalf("MarshalJSONFields: %v", err)
+	}
+	if expected := `{"items":null,"opt":null}`; compact(t, buf.Bytes()) != expected {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, buf.Bytes())
+	}
+}
+
+func TestUnmarshalJSONFields(t *testing.T) {
+	b, err := newOrder().MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+
+	o := ff.NewOrder()
+	include := fflib.NewFieldSet("id", "owner.name", "items.sku", "by_key.qty", "by_id.price", "opt")
+	if err := o.UnmarshalJSONFields(b, include); err != nil {
+		t.Fatalf("UnmarshalJSONFields: %v", err)
+	}
+
+	// Encoding the decoded order with the same set gives the selected fields
+	// back, and the others are left empty.
+	var buf fflib.Buffer
+	if err := o.MarshalJSONFields(&buf, include); err != nil {
+		t.Fatalf("MarshalJSONFields: %v", err)
+	}
+	var want fflib.Buffer
+	if err := newOrder().MarshalJSONFields(&want, include); err != nil {
+		t.Fatalf("MarshalJSONFields: %v", err)
+	}
+	if buf.String() != want.String() {
+		t.Fatalf("Expected: %s\nGot:      %s", want.Bytes(), buf.Bytes())
+	}
+	if o.Status != "" || o.Owner.Email != "" || o.Buyer != nil || o.Items[0].Qty != 0 ||
+		o.ByKey["x"].SKU != "" || o.ByID[10]["k"].SKU != "" || o.Refs != nil {
+		t.Fatalf("Expected only the selected fields, got: %#v", o)
+	}
+	if o.Opt == nil || (*o.Opt)[0].Qty != 2 {
+		t.Fatalf("Expected the whole opt field, got: %#v", o.Opt)
+	}
+
+	// A nil set decodes every field.
+	o = ff.NewOrder()
+	if err := o.UnmarshalJSONFields(b, nil); err != nil {
+		t.Fatalf("UnmarshalJSONFields: %v", err)
+	}
+	b2, _ := o.MarshalJSON()
+	if string(b2) != string(b) {
+		t.Fatalf("Expected: %s\nGot:      %s", b, b2)
+	}
+
+	// Skipped values are still checked to be valid JSON.
+	if err := ff.NewOrder().UnmarshalJSONFields([]byte(`{"status":[1,}`), include); err == nil {
+		t.Fatalf("Expected an error for the invalid skipped value")
+	}
+}
+
+func TestUnmarshalJSONFieldsInline(t *testing.T) {
+	b := []byte(`{"id":1,"address":{"city":"c","street":"s","geo":{"lat":1.5,"lng":2.5}}}`)
+
+	s := ff.NewShipment()
+	if err := s.UnmarshalJSONFields(b, fflib.NewFieldSet("address.city", "address.geo.lat")); err != nil {
+		t.Fatalf("UnmarshalJSONFields: %v", err)
+	}
+	if s.ID != 0 || s.Address.City != "c" || s.Address.Street != "" ||
+		s.Address.Geo == nil || s.Address.Geo.Lat != 1.5 || s.Address.Geo.Lng != 0 {
+		t.Fatalf("Expected only the selected members, got: %#v", s)
+	}
+
+	s = ff.NewShipment()
+	if err := s.UnmarshalJSONFields(b, fflib.NewFieldSet("address")); err != nil {
+		t.Fatalf("UnmarshalJSONFields: %v", err)
+	}
+	if s.ID != 0 || s.Address.Street != "s" || s.Address.Geo == nil || s.Address.Geo.Lng != 2.5 {
+		t.Fatalf("Expected the whole address, got: %#v", s)
+	}
+
+	// Skipped members are still checked to be valid JSON.
+	err := ff.NewShipment().UnmarshalJSONFields([]byte(`{"address":{"street":[1,}}`), fflib.NewFieldSet("address.city"))
+	if err == nil {
+		t.Fatalf("Expected an error for the invalid skipped member")
+	}
+}
@@ -0,0 +1,47 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"strings"
+)
+
+// Upper is decoded with its own UnmarshalJSON.
+type Upper string
+
+func (u *Upper) UnmarshalJSON(b []byte) error {
+	*u = Upper(strings.ToUpper(strings.Trim(string(b), `"`)))
+	return nil
+}
+
+type Record struct {
+	ID   int64 `json:"id"`
+	Meta struct {
+		Source string `json:"source"`
+		Upper  Upper  `json:"upper"`
+		Count  int    `json:"count,omitempty"`
+		Inner  struct {
+			Deep bool `json:"deep"`
+		} `json:"inner"`
+	} `json:"meta"`
+	Opt *struct {
+		Name string `json:"name"`
+	} `json:"opt"`
+	Last      string          `json:"last"`
+	fieldMark map[string]bool `xorm:"-"`
+}
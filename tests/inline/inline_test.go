@@ -0,0 +1,145 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package inline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yingshengtech/ffjson/ffjson"
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/inline/ff"
+)
+
+const recordJson = `{
+  "id": 1,
+  "meta": {"source": "s", "upper": "abc", "other": [1], "inner": {"deep": true}, "count": 2},
+  "opt": {"name": "n"},
+  "last": "l"
+}`
+
+func TestUnmarshalInline(t *testing.T) {
+	var r ff.Record
+	if err := r.UnmarshalJSON([]byte(recordJson)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	// Last follows the inline struct, so the Unmarshaler field inside it
+	// must not end its decoding.
+	if r.Meta.Source != "s" || r.Meta.Upper != "ABC" || !r.Meta.Inner.Deep || r.Meta.Count != 2 ||
+		r.Opt == nil || r.Opt.Name != "n" || r.Last != "l" {
+		t.Fatalf("Got: %+v", r)
+	}
+	if !r.MetaMark() || !r.OptMark() || !r.LastMark() {
+		t.Fatalf("Expected the inline fields to be marked, got %v", r.FieldMarks())
+	}
+
+	if err := r.UnmarshalJSON([]byte(`{"opt": null, "meta": {"upper": null}}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if r.Opt != nil || r.Meta.Upper != "ABC" {
+		t.Fatalf("Got: %+v", r)
+	}
+
+	if err := r.UnmarshalJSON([]byte(`{"meta": [1]}`)); err == nil {
+		t.Fatalf("Expected an error for an inline struct that is not an object")
+	}
+}
+
+// plainRecord has the fields of ff.Record without its generated methods.
+type plainRecord ff.Record
+
+func TestRoundTripInline(t *testing.T) {
+	var r ff.Record
+	if err := json.Unmarshal([]byte(recordJson), (*plainRecord)(&r)); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	b, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, _ := json.Marshal((*plainRecord)(&r))
+	var got bytes.Buffer
+	if err := json.Compact(&got, b); err != nil || got.String() != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b)
+	}
+
+	var r2 ff.Record
+	if err := r2.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	b2, _ := json.Marshal((*plainRecord)(&r2))
+	if string(b2) != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b2)
+	}
+}
+
+func TestSetInline(t *testing.T) {
+	r := ff.NewRecord()
+	opt := &struct {
+		Name string `json:"name"`
+	}{Name: "x"}
+	r.SetOpt(opt)
+	if r.Opt != opt || !r.OptMark() {
+		t.Fatalf("SetOpt did not set the field")
+	}
+
+	if err := r.AutoSetFieldValue(map[string]string{"last": "z", "id": "3"}); err != nil {
+		t.Fatalf("AutoSetFieldValue: %v", err)
+	}
+	if r.Last != "z" || r.ID != 3 {
+		t.Fatalf("Got: %+v", r)
+	}
+}
+
+func TestInlineDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		input  string
+		policy fflib.DuplicateKeys
+	}{
+		{`{"meta":{"source":"a","source":"b"}}`, fflib.DuplicateKeysKnown},
+		{`{"meta":{"inner":{"deep":true,"DEEP":false}}}`, fflib.DuplicateKeysKnown},
+		{`{"opt":{"name":"a","name":"b"}}`, fflib.DuplicateKeysKnown},
+		{`{"meta":{"x":1,"x":2}}`, fflib.DuplicateKeysAll},
+	}
+
+	for _, test := range tests {
+		var r ff.Record
+		if err := r.UnmarshalJSON([]byte(test.input)); err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", test.input, err)
+		}
+
+		dec := ffjson.NewDecoder()
+		dec.SetDuplicateKeys(test.policy)
+		var de *fflib.DuplicateKeyError
+		if err := dec.Decode([]byte(test.input), &r); !errors.As(err, &de) {
+			t.Fatalf("Expected *DuplicateKeyError for %s, got: %v", test.input, err)
+		}
+	}
+
+	// Each object has its own keys, and unknown keys may repeat unless
+	// all duplicates are rejected.
+	dec := ffjson.NewDecoder()
+	dec.SetDuplicateKeys(fflib.DuplicateKeysKnown)
+	var r ff.Record
+	input := `{"meta":{"source":"a","x":1,"x":2,"inner":{"deep":true}},"opt":{"name":"n"}}`
+	if err := dec.Decode([]byte(input), &r); err != nil {
+		t.Fatalf("Decode(%s): %v", input, err)
+	}
+}
@@ -0,0 +1,69 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Point is written natively as a map value.
+type Point struct {
+	X         int             `json:"x"`
+	Y         int             `json:"y"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+// Level is a named integer key.
+type Level int8
+
+// Code is a map key written with its MarshalText.
+// ffjson: skip
+type Code struct {
+	A, B string
+}
+
+func (c Code) MarshalText() ([]byte, error) {
+	if c.A == "" {
+		return nil, errors.New("empty code")
+	}
+	return []byte(c.A + "-" + c.B), nil
+}
+
+func (c *Code) UnmarshalText(b []byte) error {
+	parts := strings.SplitN(string(b), "-", 2)
+	if len(parts) != 2 {
+		return errors.New("bad code")
+	}
+	c.A, c.B = parts[0], parts[1]
+	return nil
+}
+
+type Maps struct {
+	Names     map[string]float64      `json:"names"`
+	Ints      map[int64]string        `json:"ints"`
+	Uints     map[uint16]bool         `json:"uints"`
+	Addrs     map[uintptr]string      `json:"addrs"`
+	Levels    map[Level]int           `json:"levels"`
+	Codes     map[Code]int            `json:"codes"`
+	Points    map[int64]Point         `json:"points"`
+	Nested    map[string]map[int]bool `json:"nested"`
+	Any       map[uint]interface{}    `json:"any"`
+	Empty     map[int]int             `json:"empty"`
+	fieldMark map[string]bool         `xorm:"-"`
+}
@@ -0,0 +1,161 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package mapkeys
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/yingshengtech/ffjson/ffjson"
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/mapkeys/ff"
+)
+
+// plainMaps has no methods, so encoding/json writes it by reflection.
+type plainMaps ff.Maps
+
+func newMaps() *ff.Maps {
+	m := ff.NewMaps()
+	m.Names = map[string]float64{"b": 1.5, "a": 2, "B": 3, "": 0}
+	m.Ints = map[int64]string{}
+	for i := int64(-12); i <= 12; i++ {
+		m.Ints[i*i*i*97] = "v"
+	}
+	m.Uints = map[uint16]bool{0: true, 9: false, 10: true, 65535: true, 100: false}
+	m.Addrs = map[uintptr]string{0: "a", 1 << 40: "b", 12: "c"}
+	m.Levels = map[ff.Level]int{-128: 1, 127: 2, 2: 3, -3: 4, 11: 5}
+	m.Codes = map[ff.Code]int{{A: "x", B: "1"}: 1, {A: "a", B: "2"}: 2, {A: "x", B: "0"}: 3}
+	m.Points = map[int64]ff.Point{3: {X: 1}, 20: {Y: 2}, -1: {}}
+	m.Nested = map[string]map[int]bool{"z": {10: true, 9: false}, "y": nil}
+	m.Any = map[uint]interface{}{7: "s", 70: []interface{}{1.5, nil}, 8: map[string]interface{}{"k": true}}
+	m.Empty = map[int]int{}
+	return m
+}
+
+func TestMapKeysLikeEncodingJSON(t *testing.T) {
+	m := newMaps()
+	b, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, err := json.Marshal((*plainMaps)(m))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, b); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if compact.String() != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b)
+	}
+
+	var m2 ff.Maps
+	if err := m2.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	b2, err := json.Marshal((*plainMaps)(&m2))
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	if string(b2) != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b2)
+	}
+}
+
+func TestMapKeysNil(t *testing.T) {
+	b, err := ff.NewMaps().MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected, _ := json.Marshal(&plainMaps{})
+	var compact bytes.Buffer
+	json.Compact(&compact, b)
+	if compact.String() != string(expected) {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b)
+	}
+}
+
+func TestMapKeysMarshalTextError(t *testing.T) {
+	m := newMaps()
+	m.Codes[ff.Code{}] = 4
+	for i := 0; i < 2; i++ {
+		if _, err := m.MarshalJSON(); err == nil || err.Error() != "empty code" {
+			t.Fatalf("Expected the MarshalText error, got: %v", err)
+		}
+	}
+	delete(m.Codes, ff.Code{})
+	if _, err := m.MarshalJSON(); err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+}
+
+func TestMapKeysDuplicates(t *testing.T) {
+	for _, input := range []string{
+		`{"names":{"a":1,"a":2}}`,
+		`{"ints":{"10":"x","10":"y"}}`,
+		`{"addrs":{"10":"x","10":"y"}}`,
+		`{"codes":{"x-1":1,"x-1":2}}`,
+		`{"nested":{"z":{"1":true,"1":false}}}`,
+		`{"any":{"7":{"k":1,"k":2}}}`,
+	} {
+		var m ff.Maps
+		if err := m.UnmarshalJSON([]byte(input)); err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", input, err)
+		}
+
+		// Map keys are not fields, so only DuplicateKeysAll rejects them.
+		dec := ffjson.NewDecoder()
+		dec.SetDuplicateKeys(fflib.DuplicateKeysKnown)
+		if err := dec.Decode([]byte(input), &m); err != nil {
+			t.Fatalf("Decode(%s): %v", input, err)
+		}
+
+		dec.SetDuplicateKeys(fflib.DuplicateKeysAll)
+		var de *fflib.DuplicateKeyError
+		if err := dec.Decode([]byte(input), &m); !errors.As(err, &de) {
+			t.Fatalf("Expected *DuplicateKeyError for %s, got: %v", input, err)
+		}
+	}
+}
+
+func TestMapKeysDiff(t *testing.T) {
+	m, other := newMaps(), newMaps()
+	other.Ints[1] = "w"
+	changes, err := m.Diff(other)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/ints" {
+		t.Fatalf("Expected a change of /ints, got: %+v", changes)
+	}
+
+	// Values that fail to encode are errors, not omitted values.
+	other.Codes[ff.Code{}] = 4
+	if _, err := m.Diff(other); err == nil || err.Error() != "empty code" {
+		t.Fatalf("Expected the MarshalText error, got: %v", err)
+	}
+	delete(other.Codes, ff.Code{})
+	m.Names["nan"] = math.NaN()
+	if _, err := m.Diff(other); err == nil {
+		t.Fatalf("Expected an error for NaN")
+	}
+}
@@ -0,0 +1,72 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"fmt"
+)
+
+type Address struct {
+	City      string          `json:"city"`
+	Zip       int             `json:"zip"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+type Item struct {
+	Name      string          `json:"name"`
+	Price     float64         `json:"price"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+// Version is a map key decoded with its UnmarshalText.
+// ffjson: skip
+type Version struct {
+	Major, Minor int
+}
+
+func (v Version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d", v.Major, v.Minor)), nil
+}
+
+func (v *Version) UnmarshalText(b []byte) error {
+	_, err := fmt.Sscanf(string(b), "%d.%d", &v.Major, &v.Minor)
+	return err
+}
+
+type Order struct {
+	ID       int64                        `json:"id"`
+	Home     Address                      `json:"address"`
+	Work     *Address                     `json:"work"`
+	Items    []Item                       `json:"items"`
+	Refs     []*Item                      `json:"refs"`
+	Grid     [2][]int                     `json:"grid"`
+	Tags     map[string]string            `json:"tags"`
+	Nested   map[string]map[string]int    `json:"nested"`
+	Deep     map[string]map[string][]Item `json:"deep"`
+	Slashed  int                          `json:"a/b~c"`
+	IntMap   map[int64]string             `json:"mi"`
+	Versions map[Version]Item             `json:"versions"`
+	Inline   struct {
+		X  int `json:"x"`
+		In *struct {
+			A string `json:"a"`
+		} `json:"in"`
+	} `json:"inline"`
+	Any       interface{}     `json:"any"`
+	fieldMark map[string]bool `xorm:"-"`
+}
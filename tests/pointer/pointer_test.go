@@ -0,0 +1,172 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package pointer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/pointer/ff"
+)
+
+const orderJson = `{
+  "id": 1,
+  "address": {"city": "Oslo", "zip": 150},
+  "items": [{"name": "a", "price": 1.5}],
+  "refs": [{"name": "r"}],
+  "grid": [[1, 2], [3]],
+  "tags": {"k": "v"},
+  "nested": {"a": {"b": 2}},
+  "deep": {"a": {"b": [{"name": "d"}]}},
+  "a/b~c": 9,
+  "mi": {"10": "ten", "-1": "neg"},
+  "versions": {"1.2": {"name": "v"}},
+  "inline": {"x": 3, "in": {"a": "deep"}},
+  "any": {"b": 1}
+}`
+
+func TestGetPointer(t *testing.T) {
+	var o ff.Order
+	if err := o.UnmarshalJSON([]byte(orderJson)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	tests := []struct {
+		ptr string
+		val interface{}
+	}{
+		{"/id", int64(1)},
+		{"/address/city", "Oslo"},
+		{"/items/0/price", 1.5},
+		{"/refs/0/name", "r"},
+		{"/grid/1/0", 3},
+		{"/tags/k", "v"},
+		{"/nested/a/b", 2},
+		{"/deep/a/b/0/name", "d"},
+		{"/a~1b~0c", 9},
+		{"/mi/10", "ten"},
+		{"/mi/-1", "neg"},
+		{"/versions/1.2/name", "v"},
+		{"/inline/x", 3},
+		{"/inline/in/a", "deep"},
+		{"/any", map[string]interface{}{"b": 1.0}},
+	}
+	for _, test := range tests {
+		v, err := o.GetPointer(test.ptr)
+		if err != nil {
+			t.Fatalf("GetPointer(%q): %v", test.ptr, err)
+		}
+		if !reflect.DeepEqual(v, test.val) {
+			t.Fatalf("GetPointer(%q): expected %v, got %v", test.ptr, test.val, v)
+		}
+	}
+
+	// interface{} values are leaves.
+	for _, ptr := range []string{"/work/city", "/items/1", "/nested/a/x", "/nested/x/b", "/missing",
+		"/mi/x", "/mi/11", "/versions/1", "/inline/y", "/any/b"} {
+		if _, err := o.GetPointer(ptr); err != fflib.ErrPointerNotFound {
+			t.Fatalf("GetPointer(%q): expected ErrPointerNotFound, got %v", ptr, err)
+		}
+	}
+}
+
+func TestSetPointer(t *testing.T) {
+	o := ff.NewOrder()
+	sets := []struct {
+		ptr string
+		raw string
+	}{
+		{"/address/city", `"Bergen"`},
+		{"/work/zip", `5000`},
+		{"/items/-", `{"name": "new", "price": 2}`},
+		{"/items/0/price", `3.5`},
+		{"/nested/a", `{"b": 1}`},
+		{"/nested/a/b", `4`},
+		{"/nested/a/c", `5`},
+		{"/nested/x", `{"y": 6}`},
+		{"/deep/a", `{"b": []}`},
+		{"/deep/a/b/-", `{"name": "d"}`},
+		{"/deep/a/b/0/price", `7`},
+		{"/mi/5", `"five"`},
+		{"/versions/2.0", `{"name": "w"}`},
+		{"/versions/2.0/price", `8`},
+		{"/inline/x", `1`},
+		{"/inline/in/a", `"b"`},
+		{"/any", `[true]`},
+	}
+	for _, set := range sets {
+		if err := o.SetPointer(set.ptr, []byte(set.raw)); err != nil {
+			t.Fatalf("SetPointer(%q): %v", set.ptr, err)
+		}
+	}
+
+	expected := ff.Order{
+		Home:     ff.Address{City: "Bergen"},
+		Work:     &ff.Address{Zip: 5000},
+		Items:    []ff.Item{{Name: "new", Price: 3.5}},
+		Nested:   map[string]map[string]int{"a": {"b": 4, "c": 5}, "x": {"y": 6}},
+		Deep:     map[string]map[string][]ff.Item{"a": {"b": {{Name: "d", Price: 7}}}},
+		IntMap:   map[int64]string{5: "five"},
+		Versions: map[ff.Version]ff.Item{{Major: 2}: {Name: "w", Price: 8}},
+		Any:      []interface{}{true},
+	}
+	expected.Inline.X = 1
+	expected.Inline.In = &struct {
+		A string `json:"a"`
+	}{A: "b"}
+	// The field marks differ, so compare the encoding.
+	eb, _ := json.Marshal(&expected)
+	gb, _ := json.Marshal(o)
+	if string(eb) != string(gb) {
+		t.Fatalf("Expected: %s\nGot: %s", eb, gb)
+	}
+
+	if !o.HomeMark() || !o.WorkMark() || !o.ItemsMark() || !o.NestedMark() || !o.DeepMark() ||
+		!o.IntMapMark() || !o.VersionsMark() || !o.InlineMark() || !o.AnyMark() || o.IDMark() {
+		t.Fatalf("Expected the set fields to be marked, got %v", o.FieldMarks())
+	}
+
+	for _, ptr := range []string{"/nested/zz/b/c", "/mi/x", "/versions/x", "/inline/y", "/any/b"} {
+		if err := o.SetPointer(ptr, []byte(`1`)); err != fflib.ErrPointerNotFound {
+			t.Fatalf("SetPointer(%q): expected ErrPointerNotFound, got %v", ptr, err)
+		}
+	}
+}
+
+func TestSetPointerError(t *testing.T) {
+	o := ff.NewOrder()
+	for _, set := range []struct {
+		ptr    string
+		raw    string
+		offset int
+	}{
+		{"/id", `"x"`, 3},
+		{"/items/-", `{"price": "x"}`, 13},
+		{"/nested/a", `{"b": "x"}`, 9},
+		{"/inline/x", `true`, 4},
+	} {
+		err := o.SetPointer(set.ptr, []byte(set.raw))
+		var le *fflib.LexerError
+		if !errors.As(err, &le) || le.Offset() != set.offset {
+			t.Fatalf("SetPointer(%q): expected a *LexerError at %d, got %v", set.ptr, set.offset, err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+type Payment struct {
+	Price     float64         `json:"price" ffjson:",precision=2"`
+	Amount    float64         `json:"amount,string" ffjson:",precision=2"`
+	Rate      float32         `json:"rate" ffjson:",precision=3"`
+	Fee       *float64        `json:"fee" ffjson:",precision=2"`
+	Tax       *float64        `json:"tax,string" ffjson:",precision=2"`
+	fieldMark map[string]bool `xorm:"-"`
+}
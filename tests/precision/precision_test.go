@@ -0,0 +1,68 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package precision
+
+import (
+	"math"
+	"testing"
+
+	ff "github.com/yingshengtech/ffjson/tests/precision/ff"
+)
+
+func TestPrecision(t *testing.T) {
+	fee, tax := 3.1, 0.2
+	p := ff.NewPayment()
+	p.Price, p.Amount, p.Rate = 12.5, 12.5, 0.0625
+	p.Fee, p.Tax = &fee, &tax
+
+	b, err := p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"price":12.50,"amount":"12.50","rate":0.062,"fee":3.10,"tax":"0.20"}`
+	if string(b) != expected {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+	}
+
+	p2 := ff.NewPayment()
+	if err := p2.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if p2.Price != 12.5 || p2.Amount != 12.5 || p2.Rate != 0.062 || *p2.Fee != 3.1 || *p2.Tax != 0.2 {
+		t.Fatalf("Unexpected round trip: %#v", p2)
+	}
+
+	p = ff.NewPayment()
+	b, err = p.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if expected := `{"price":0.00,"amount":"0.00","rate":0.000,"fee":null,"tax":null}`; string(b) != expected {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+	}
+}
+
+func TestPrecisionInvalid(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1)} {
+		p := ff.NewPayment()
+		p.Amount = f
+		if _, err := p.MarshalJSON(); err == nil {
+			t.Fatalf("Expected an error for %v", f)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/yingshengtech/ffjson/ffjson"
+)
+
+// Secret is a variant interface.
+type Secret interface {
+	Secret()
+}
+
+func init() {
+	ffjson.RegisterVariant((*Secret)(nil), "kind", "contact", Contact{})
+}
+
+type Contact struct {
+	Name      string          `json:"name"`
+	Phone     string          `json:"phone" ffjson:",mask=3,4"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+func (c Contact) Secret() {}
+
+// Holder has no generated code.
+// ffjson: skip
+type Holder struct {
+	Contact Contact `json:"contact"`
+}
+
+type Account struct {
+	ID        int64                `json:"id"`
+	Token     string               `json:"token" ffjson:",redact"`
+	Main      Contact              `json:"main"`
+	Contacts  map[string]Contact   `json:"contacts"`
+	Refs      map[int]*Contact     `json:"refs"`
+	Groups    map[string][]Contact `json:"groups"`
+	Extra     interface{}          `json:"extra"`
+	Secret    Secret               `json:"secret" ffjson:",variant"`
+	Holders   map[string]Holder    `json:"holders"`
+	fieldMark map[string]bool      `xorm:"-"`
+}
@@ -0,0 +1,94 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/redact/ff"
+)
+
+func newAccount() *ff.Account {
+	c := ff.Contact{Name: "n", Phone: "13812345678"}
+	a := ff.NewAccount()
+	a.ID = 1
+	a.Token = "t0ken"
+	a.Main = c
+	a.Contacts = map[string]ff.Contact{"a": c}
+	a.Refs = map[int]*ff.Contact{2: &c, 1: nil}
+	a.Groups = map[string][]ff.Contact{"g": {c, c}}
+	a.Extra = map[string]interface{}{"c": c, "p": &c, "l": []interface{}{1.5, c}}
+	a.Secret = c
+	return a
+}
+
+func compact(t *testing.T, b []byte) string {
+	var out bytes.Buffer
+	if err := json.Compact(&out, b); err != nil {
+		t.Fatalf("Compact(%s): %v", b, err)
+	}
+	return out.String()
+}
+
+func TestMarshalJSONRedacted(t *testing.T) {
+	a := newAccount()
+	b, err := a.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	c := `{"name":"n","phone":"138****5678"}`
+	expected := `{"id":1,"token":"***","main":` + c + `,"contacts":{"a":` + c + `},` +
+		`"refs":{"1":null,"2":` + c + `},"groups":{"g":[` + c + `,` + c + `]},` +
+		`"extra":{"c":` + c + `,"l":[1.5,` + c + `],"p":` + c + `},` +
+		`"secret":{"kind":"contact","name":"n","phone":"138****5678"},"holders":null}`
+	if compact(t, b) != expected {
+		t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+	}
+
+	// MarshalJSON is unchanged.
+	b, err = a.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	if bytes.Contains(b, []byte("***")) || !bytes.Contains(b, []byte(`"token":"t0ken"`)) {
+		t.Fatalf("Expected no redaction, got: %s", b)
+	}
+}
+
+func TestMarshalJSONRedactedNoEncoder(t *testing.T) {
+	a := newAccount()
+	a.Holders = map[string]ff.Holder{"h": {Contact: a.Main}}
+	_, err := a.MarshalJSONRedacted()
+	if _, ok := err.(*fflib.RedactError); !ok {
+		t.Fatalf("Expected *fflib.RedactError, got: %v", err)
+	}
+
+	// Values without tagged fields are still written by encoding/json.
+	a.Holders = nil
+	a.Extra = map[string]interface{}{"h": struct{ A string }{"x"}}
+	b, err := a.MarshalJSONRedacted()
+	if err != nil {
+		t.Fatalf("MarshalJSONRedacted: %v", err)
+	}
+	if !bytes.Contains(b, []byte(`"extra":{"h":{"A":"x"}}`)) {
+		t.Fatalf("Got: %s", b)
+	}
+}
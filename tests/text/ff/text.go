@@ -0,0 +1,59 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ID is written with its MarshalText, and has no MarshalJSON.
+// ffjson: skip
+type ID struct {
+	Kind string
+	N    int
+}
+
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.Kind + "-" + strconv.Itoa(id.N)), nil
+}
+
+func (id *ID) UnmarshalText(b []byte) error {
+	parts := strings.SplitN(string(b), "-", 2)
+	if len(parts) != 2 {
+		return errors.New("bad id")
+	}
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+	id.Kind, id.N = parts[0], n
+	return nil
+}
+
+type Host struct {
+	IP        net.IP          `json:"ip"`
+	IPPtr     *net.IP         `json:"ip_ptr"`
+	IPNil     *net.IP         `json:"ip_nil"`
+	ID        ID              `json:"id"`
+	IDPtr     *ID             `json:"id_ptr"`
+	IDNil     *ID             `json:"id_nil"`
+	fieldMark map[string]bool `xorm:"-"`
+}
@@ -0,0 +1,95 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package text
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	ff "github.com/yingshengtech/ffjson/tests/text/ff"
+)
+
+// plainHost has no methods, so encoding/json writes it by reflection.
+type plainHost ff.Host
+
+func newHost() *ff.Host {
+	ip := net.ParseIP("2001:db8::1")
+	h := ff.NewHost()
+	h.IP = net.ParseIP("10.0.0.1").To4()
+	h.IPPtr = &ip
+	h.ID = ff.ID{Kind: "host", N: 7}
+	h.IDPtr = &ff.ID{Kind: "rack", N: 12}
+	return h
+}
+
+func TestTextLikeEncodingJSON(t *testing.T) {
+	for _, h := range []*ff.Host{newHost(), ff.NewHost()} {
+		b, err := h.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected, err := json.Marshal((*plainHost)(h))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if string(b) != string(expected) {
+			t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+		}
+
+		h2 := ff.NewHost()
+		if err := h2.UnmarshalJSON(b); err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", b, err)
+		}
+		b2, err := json.Marshal((*plainHost)(h2))
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if string(b2) != string(expected) {
+			t.Fatalf("Expected: %s\nGot:      %s", expected, b2)
+		}
+	}
+}
+
+func TestTextNull(t *testing.T) {
+	h := newHost()
+	if err := h.UnmarshalJSON([]byte(`{"ip_ptr":null,"id_ptr":null}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if h.IPPtr != nil || h.IDPtr != nil || h.ID.N != 7 {
+		t.Fatalf("Expected null to clear only the pointers, got: %#v", h)
+	}
+}
+
+func TestTextRejectsNonString(t *testing.T) {
+	for _, input := range []string{
+		`{"ip":1}`,
+		`{"ip_ptr":true}`,
+		`{"id":["host-7"]}`,
+		`{"id_ptr":{"kind":"host"}}`,
+		`{"id":"host"}`,
+	} {
+		if err := ff.NewHost().UnmarshalJSON([]byte(input)); err == nil {
+			t.Fatalf("Expected an error for %s", input)
+		}
+		var plain plainHost
+		if err := json.Unmarshal([]byte(input), &plain); err == nil {
+			t.Fatalf("encoding/json accepts %s", input)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"time"
+)
+
+type Event struct {
+	At        time.Time       `json:"at"`
+	AtPtr     *time.Time      `json:"at_ptr"`
+	Day       time.Time       `json:"day" ffjson:",layout=2006-01-02 15:04:05"`
+	DayPtr    *time.Time      `json:"day_ptr" ffjson:",layout=2006-01-02 15:04:05"`
+	Stamp     time.Time       `json:"stamp" ffjson:",layout=Jan 2, 2006"`
+	Unix      time.Time       `json:"unix" ffjson:",unix"`
+	UnixPtr   *time.Time      `json:"unix_ptr" ffjson:",unix"`
+	Milli     time.Time       `json:"milli" ffjson:",unixmilli"`
+	MilliPtr  *time.Time      `json:"milli_ptr" ffjson:",unixmilli"`
+	fieldMark map[string]bool `xorm:"-"`
+}
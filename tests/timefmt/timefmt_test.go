@@ -0,0 +1,158 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package timefmt
+
+import (
+	"testing"
+	"time"
+
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/timefmt/ff"
+)
+
+var cst = time.FixedZone("CST", 8*3600)
+
+// withLocation runs f with fflib.TimeLocation set to loc.
+func withLocation(loc *time.Location, f func()) {
+	saved := fflib.TimeLocation
+	fflib.TimeLocation = loc
+	defer func() { fflib.TimeLocation = saved }()
+	f()
+}
+
+func TestTimeFormats(t *testing.T) {
+	withLocation(cst, func() {
+		tm := time.Date(2024, 3, 5, 14, 7, 9, 123000000, cst)
+		e := ff.NewEvent()
+		e.At, e.AtPtr = tm, &tm
+		e.Day, e.DayPtr = tm, &tm
+		e.Stamp = tm
+		e.Unix, e.UnixPtr = tm, &tm
+		e.Milli, e.MilliPtr = tm, &tm
+
+		b, err := e.MarshalJSON()
+		if err != nil {
+			t.Fatalf("MarshalJSON: %v", err)
+		}
+		expected := `{"at":"2024-03-05T14:07:09.123+08:00","at_ptr":"2024-03-05T14:07:09.123+08:00",` +
+			`"day":"2024-03-05 14:07:09","day_ptr":"2024-03-05 14:07:09","stamp":"Mar 5, 2024",` +
+			`"unix":1709618829,"unix_ptr":1709618829,"milli":1709618829123,"milli_ptr":1709618829123}`
+		if string(b) != expected {
+			t.Fatalf("Expected: %s\nGot:      %s", expected, b)
+		}
+
+		e2 := ff.NewEvent()
+		if err := e2.UnmarshalJSON(b); err != nil {
+			t.Fatalf("UnmarshalJSON: %v", err)
+		}
+		seconds := tm.Truncate(time.Second)
+		for _, c := range []struct {
+			name     string
+			got      time.Time
+			expected time.Time
+		}{
+			{"at", e2.At, tm},
+			{"at_ptr", *e2.AtPtr, tm},
+			{"day", e2.Day, seconds},
+			{"day_ptr", *e2.DayPtr, seconds},
+			{"stamp", e2.Stamp, time.Date(2024, 3, 5, 0, 0, 0, 0, cst)},
+			{"unix", e2.Unix, seconds},
+			{"unix_ptr", *e2.UnixPtr, seconds},
+			{"milli", e2.Milli, tm},
+			{"milli_ptr", *e2.MilliPtr, tm},
+		} {
+			if !c.got.Equal(c.expected) {
+				t.Fatalf("%s: expected %v, got %v", c.name, c.expected, c.got)
+			}
+		}
+		if e2.Day.Location() != cst || e2.Unix.Location() != cst || e2.Milli.Location() != cst {
+			t.Fatalf("Expected times in TimeLocation, got: %v %v %v", e2.Day.Location(), e2.Unix.Location(), e2.Milli.Location())
+		}
+	})
+}
+
+func TestTimeLocation(t *testing.T) {
+	input := []byte(`{"day":"2024-03-05 14:07:09","unix":1709618829}`)
+	for _, loc := range []*time.Location{time.UTC, cst} {
+		withLocation(loc, func() {
+			e := ff.NewEvent()
+			if err := e.UnmarshalJSON(input); err != nil {
+				t.Fatalf("UnmarshalJSON: %v", err)
+			}
+			if expected := time.Date(2024, 3, 5, 14, 7, 9, 0, loc); !e.Day.Equal(expected) {
+				t.Fatalf("Expected the layout parsed in %v: %v, got %v", loc, expected, e.Day)
+			}
+			if e.Unix.Location() != loc || e.Unix.Unix() != 1709618829 {
+				t.Fatalf("Expected the timestamp in %v, got %v", loc, e.Unix)
+			}
+
+			b, err := e.MarshalJSON()
+			if err != nil {
+				t.Fatalf("MarshalJSON: %v", err)
+			}
+			e2 := ff.NewEvent()
+			if err := e2.UnmarshalJSON(b); err != nil || !e2.Day.Equal(e.Day) {
+				t.Fatalf("Expected the layout to round-trip in %v, got %v (%v)", loc, e2.Day, err)
+			}
+		})
+	}
+}
+
+func TestTimeNull(t *testing.T) {
+	e := ff.NewEvent()
+	expected := `{"at":"0001-01-01T00:00:00Z","at_ptr":null,"day":"0001-01-01 00:00:00","day_ptr":null,` +
+		`"stamp":"Jan 1, 0001","unix":-62135596800,"unix_ptr":null,"milli":-62135596800000,"milli_ptr":null}`
+	withLocation(time.UTC, func() {
+		if b, err := e.MarshalJSON(); err != nil || string(b) != expected {
+			t.Fatalf("Expected: %s\nGot:      %s (%v)", expected, b, err)
+		}
+	})
+
+	tm := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	e.At, e.Day, e.Unix, e.Milli = tm, tm, tm, tm
+	e.AtPtr, e.DayPtr, e.UnixPtr, e.MilliPtr = &tm, &tm, &tm, &tm
+	err := e.UnmarshalJSON([]byte(`{"at":null,"at_ptr":null,"day":null,"day_ptr":null,` +
+		`"unix":null,"unix_ptr":null,"milli":null,"milli_ptr":null}`))
+	if err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if e.AtPtr != nil || e.DayPtr != nil || e.UnixPtr != nil || e.MilliPtr != nil {
+		t.Fatalf("Expected null to clear the pointers, got: %#v", e)
+	}
+	// Like encoding/json, null leaves a time.Time value unchanged.
+	if !e.At.Equal(tm) || !e.Day.Equal(tm) || !e.Unix.Equal(tm) || !e.Milli.Equal(tm) {
+		t.Fatalf("Expected null to keep the values, got: %#v", e)
+	}
+}
+
+func TestTimeInvalid(t *testing.T) {
+	for _, input := range []string{
+		`{"at":"2024-03-05"}`,
+		`{"day":"2024/03/05 14:07:09"}`,
+		`{"day_ptr":"2024-03-05T14:07:09Z"}`,
+		`{"stamp":"2024-03-05"}`,
+		`{"day":1709618829}`,
+		`{"unix":"1709618829"}`,
+		`{"unix_ptr":1.5}`,
+		`{"milli":true}`,
+	} {
+		if err := ff.NewEvent().UnmarshalJSON([]byte(input)); err == nil {
+			t.Fatalf("Expected an error for %s", input)
+		}
+	}
+}
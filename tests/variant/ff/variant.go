@@ -0,0 +1,55 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package ff
+
+import (
+	"github.com/yingshengtech/ffjson/ffjson"
+)
+
+func init() {
+	ffjson.RegisterVariant((*Shape)(nil), "kind", "circle", Circle{})
+	ffjson.RegisterVariant((*Shape)(nil), "kind", "square", &Square{})
+}
+
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	R         float64         `json:"r"`
+	Label     string          `json:"label"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+func (c Circle) Area() float64 { return 3 * c.R * c.R }
+
+type Square struct {
+	Side      float64         `json:"side"`
+	Inner     *Square         `json:"inner"`
+	fieldMark map[string]bool `xorm:"-"`
+}
+
+func (s *Square) Area() float64 { return s.Side * s.Side }
+
+type Drawing struct {
+	Name      string          `json:"name"`
+	Main      Shape           `json:"main" ffjson:",variant"`
+	Alt       Shape           `json:"alt" ffjson:",variant=type"`
+	Note      interface{}     `json:"note"`
+	fieldMark map[string]bool `xorm:"-"`
+}
@@ -0,0 +1,190 @@
+/**
+ *  Copyright 2014 Paul Querna
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ *
+ */
+
+package variant
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yingshengtech/ffjson/ffjson"
+	fflib "github.com/yingshengtech/ffjson/fflib/v1"
+	ff "github.com/yingshengtech/ffjson/tests/variant/ff"
+)
+
+func TestVariantRoundTrip(t *testing.T) {
+	d := ff.Drawing{
+		Name: "d",
+		Main: ff.Circle{R: 2, Label: "c"},
+		Alt:  &ff.Square{Side: 3, Inner: &ff.Square{Side: 1}},
+	}
+	b, err := d.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON: %v", err)
+	}
+	expected := `{"name":"d","main":{"kind":"circle","r":2,"label":"c"},` +
+		`"alt":{"type":"square","side":3,"inner":{"side":1,"inner":null}},"note":null}`
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, b); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if compact.String() != expected {
+		t.Fatalf("Expected: %s\nGot: %s", expected, b)
+	}
+
+	var d2 ff.Drawing
+	if err := d2.UnmarshalJSON(b); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	c, ok := d2.Main.(ff.Circle)
+	if !ok || c.R != 2 || c.Label != "c" {
+		t.Fatalf("Main: %#v", d2.Main)
+	}
+	s, ok := d2.Alt.(*ff.Square)
+	if !ok || s.Side != 3 || s.Inner == nil || s.Inner.Side != 1 {
+		t.Fatalf("Alt: %#v", d2.Alt)
+	}
+
+	// The discriminator may come last, and null clears the field.
+	if err := d2.UnmarshalJSON([]byte(`{"main":{"r":5,"kind":"circle"},"alt":null}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if c, ok := d2.Main.(ff.Circle); !ok || c.R != 5 || d2.Alt != nil {
+		t.Fatalf("Got: %#v", d2)
+	}
+}
+
+func TestVariantEncoderPolicies(t *testing.T) {
+	d := ff.Drawing{Main: ff.Circle{Label: "é<\xff"}}
+
+	var w bytes.Buffer
+	enc := ffjson.NewEncoder(&w)
+	enc.SetEscapeASCII(true)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(&d); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.Contains(w.Bytes(), []byte(`"label":"\u00e9<\ufffd"`)) {
+		t.Fatalf("Expected the escaping of the encoder inside the variant, got: %s", w.Bytes())
+	}
+
+	enc.SetUTF8(fflib.UTF8Reject)
+	if _, ok := enc.Encode(&d).(*fflib.InvalidUTF8Error); !ok {
+		t.Fatalf("Expected invalid UTF-8 inside the variant to be rejected")
+	}
+}
+
+func TestVariantDecoderSettings(t *testing.T) {
+	deep := []byte(`{"alt":{"type":"square","inner":{"inner":{"inner":{"side":1}}}}}`)
+
+	dec := ffjson.NewDecoder()
+	dec.SetLimits(fflib.Limits{MaxDepth: 4})
+	var d ff.Drawing
+	err := dec.Decode(deep, &d)
+	if !fflib.IsLimitError(err) {
+		t.Fatalf("Expected the depth limit to cover the variant, got: %v", err)
+	}
+	dec.SetLimits(fflib.Limits{MaxDepth: 5})
+	if err := dec.Decode(deep, &d); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	dec.UseStrict()
+	dec.SetLimits(fflib.Limits{MaxDepth: 4})
+	err = dec.Decode(deep, &d)
+	if !fflib.IsLimitError(err) {
+		t.Fatalf("Expected the depth limit in strict mode, got: %v", err)
+	}
+	dec.SetLimits(fflib.Limits{MaxDepth: 5})
+	if err := dec.Decode(append(deep, '}'), &d); err == nil {
+		t.Fatalf("Expected trailing data to be rejected in strict mode")
+	}
+	if err := dec.Decode([]byte(`{"name":"n",}`), &d); err == nil {
+		t.Fatalf("Expected a trailing comma to be rejected in strict mode")
+	}
+
+	dec = ffjson.NewDecoder()
+	dec.SetUTF8(fflib.UTF8Reject)
+	err = dec.Decode([]byte("{\"main\":{\"kind\":\"circle\",\"label\":\"\xff\"}}"), &d)
+	var ue *fflib.InvalidUTF8Error
+	if !errors.As(err, &ue) {
+		t.Fatalf("Expected invalid UTF-8 inside the variant to be rejected, got: %v", err)
+	}
+
+	d = ff.Drawing{}
+	err = d.UnmarshalJSONFields([]byte(`{"name":"n","main":{"kind":"circle","r":1,"label":"l"}}`),
+		fflib.NewFieldSet("main.r"))
+	if err != nil {
+		t.Fatalf("UnmarshalJSONFields: %v", err)
+	}
+	if c, ok := d.Main.(ff.Circle); !ok || c.R != 1 || c.Label != "" || d.Name != "" {
+		t.Fatalf("Expected only main.r, got: %#v", d)
+	}
+}
+
+func TestVariantDuplicateKeys(t *testing.T) {
+	var d ff.Drawing
+	for _, input := range []string{
+		`{"main":{"kind":"circle","r":1,"r":2}}`,
+		`{"main":{"kind":"circle","r":1,"kind":"circle"}}`,
+	} {
+		if err := d.UnmarshalJSON([]byte(input)); err != nil {
+			t.Fatalf("UnmarshalJSON(%s): %v", input, err)
+		}
+
+		dec := ffjson.NewDecoder()
+		dec.SetDuplicateKeys(fflib.DuplicateKeysKnown)
+		var de *fflib.DuplicateKeyError
+		if err := dec.Decode([]byte(input), &d); !errors.As(err, &de) {
+			t.Fatalf("Expected *DuplicateKeyError for %s, got: %v", input, err)
+		}
+	}
+}
+
+func TestVariantPassedErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		limits fflib.Limits
+	}{
+		// Limits and duplicate keys inside a variant, through its nested
+		// generated decoder, and inside an interface.
+		{`{"name":"n","alt":{"type":"square","inner":{"inner":{"inner":{}}}}}`, fflib.Limits{MaxDepth: 4}},
+		{`{"name":"n","alt":{"type":"square","inner":{"side":1,"side":2}}}`, fflib.Limits{}},
+		{`{"name":"n","note":[[[[1]]]]}`, fflib.Limits{MaxDepth: 3}},
+		{`{"name":"n","note":{"a":1,"b":2}}`, fflib.Limits{MaxElements: 1}},
+	}
+
+	for _, test := range tests {
+		dec := ffjson.NewDecoder()
+		dec.SetLimits(test.limits)
+		dec.SetDuplicateKeys(fflib.DuplicateKeysKnown)
+		var d ff.Drawing
+		err := dec.Decode([]byte(test.input), &d)
+		le, ok := err.(*fflib.LexerError)
+		if !ok || !fflib.PassError(err) {
+			t.Fatalf("Expected a *LexerError passed through for %s, got: %T %v", test.input, err, err)
+		}
+		if _, ok := le.Unwrap().(*fflib.LexerError); ok {
+			t.Fatalf("Expected a single *LexerError for %s, got: %v", test.input, err)
+		}
+		if le.Offset() <= len(`{"name":"n",`) || le.Offset() > len(test.input) {
+			t.Fatalf("Expected the error inside the field for %s, got offset %d", test.input, le.Offset())
+		}
+	}
+}